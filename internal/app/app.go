@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
@@ -15,9 +16,11 @@ import (
 
 	"github.com/LywwKkA-aD/golicensemanager/internal/app/handler"
 	"github.com/LywwKkA-aD/golicensemanager/internal/config"
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
 	"github.com/LywwKkA-aD/golicensemanager/internal/middleware"
 	"github.com/LywwKkA-aD/golicensemanager/internal/repository/postgres"
 	"github.com/LywwKkA-aD/golicensemanager/internal/service"
+	"github.com/LywwKkA-aD/golicensemanager/pkg/geoip"
 )
 
 type App struct {
@@ -26,6 +29,8 @@ type App struct {
 	db         *gorm.DB
 	router     *gin.Engine
 	httpServer *http.Server
+	jobs       []job
+	jobsWG     sync.WaitGroup
 }
 
 func NewApp(cfg *config.Config, logger *zap.SugaredLogger) (*App, error) {
@@ -50,29 +55,125 @@ func NewApp(cfg *config.Config, logger *zap.SugaredLogger) (*App, error) {
 	// Initialize router
 	router := gin.New()
 	router.Use(gin.Recovery())
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 
 	// Initialize repositories
 	appRepo := postgres.NewApplicationRepository(db)
 	licenseRepo := postgres.NewLicenseRepository(db)
 	licenseTypeRepo := postgres.NewLicenseTypeRepository(db)
 	clientRepo := postgres.NewClientRepository(db)
+	signingKeyRepo := postgres.NewSigningKeyRepository(db)
+	activationRepo := postgres.NewActivationRepository(db)
+	leaseRepo := postgres.NewLeaseRepository(db)
+	usageRepo := postgres.NewUsageRepository(db)
+	featureRepo := postgres.NewFeatureDefinitionRepository(db)
+	riskRepo := postgres.NewRiskRepository(db)
+	noticeRepo := postgres.NewExpiryNoticeRepository(db)
+	addonRepo := postgres.NewLicenseAddonRepository(db)
+	subscriptionRepo := postgres.NewSubscriptionRepository(db)
+	webhookRepo := postgres.NewWebhookRepository(db)
+	outboxRepo := postgres.NewOutboxRepository(db)
+	transactor := postgres.NewTransactor(db)
+
+	// Initialize event publishing
+	webhookService := service.NewWebhookService(webhookRepo, service.WebhookConfig{
+		Timeout:     cfg.Webhooks.Timeout,
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		MinBackoff:  cfg.Webhooks.MinBackoff,
+		MaxBackoff:  cfg.Webhooks.MaxBackoff,
+		BatchSize:   cfg.Webhooks.BatchSize,
+	}, logger)
+	eventBus := events.NewBus()
+
+	// Services write events to the outbox, which publishes them to the sinks
+	outboxService := service.NewOutboxService(
+		outboxRepo,
+		events.MultiPublisher{events.NewLogPublisher(logger), webhookService, eventBus},
+		service.OutboxConfig{
+			BatchSize:  cfg.Outbox.BatchSize,
+			MinBackoff: cfg.Outbox.MinBackoff,
+			MaxBackoff: cfg.Outbox.MaxBackoff,
+			Retention:  cfg.Outbox.Retention,
+		},
+		logger,
+	)
 
 	// Initialize services
-	appService := service.NewApplicationService(appRepo, logger)
-	licenseService := service.NewLicenseService(licenseRepo, licenseTypeRepo, logger)
-	clientService := service.NewClientService(clientRepo, licenseRepo, logger)
+	keyEncryptionKey, err := cfg.Signing.GetKeyEncryptionKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing key encryption key: %w", err)
+	}
+	keyService := service.NewKeyService(signingKeyRepo, keyEncryptionKey, logger)
+	appService := service.NewApplicationService(appRepo, keyService, logger)
+	featureService := service.NewFeatureService(featureRepo, logger)
+	licenseTypeService := service.NewLicenseTypeService(licenseTypeRepo, featureService, logger)
+	licenseService := service.NewLicenseService(
+		licenseRepo, appRepo, licenseTypeRepo, clientRepo, activationRepo, leaseRepo, usageRepo, noticeRepo,
+		addonRepo, subscriptionRepo, transactor, featureService, keyService, outboxService, logger,
+	)
+	clientService := service.NewClientService(clientRepo, licenseRepo, transactor, outboxService, logger)
+	subscriptionService := service.NewSubscriptionService(
+		subscriptionRepo, licenseRepo, licenseService, transactor, outboxService, logger,
+	)
+
+	// Impossible travel detection needs a GeoIP database
+	var geoLocator service.GeoLocator
+	if cfg.Anomaly.GeoIPFile != "" {
+		geoDB, err := geoip.Load(cfg.Anomaly.GeoIPFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load geoip database: %w", err)
+		}
+		geoLocator = geoDB
+	}
+	anomalyService := service.NewAnomalyService(riskRepo, licenseService, geoLocator, service.AnomalyConfig{
+		Window:                  cfg.Anomaly.Window,
+		MaxDistinctIPs:          cfg.Anomaly.MaxDistinctIPs,
+		MaxDistinctFingerprints: cfg.Anomaly.MaxDistinctFingerprints,
+		BurstWindow:             cfg.Anomaly.BurstWindow,
+		BurstThreshold:          cfg.Anomaly.BurstThreshold,
+		MaxTravelSpeed:          cfg.Anomaly.MaxTravelSpeed,
+		AutoSuspendScore:        cfg.Anomaly.AutoSuspendScore,
+	}, logger)
+
+	// Make sure a signing key exists, importing the configured one on first start
+	seedKey, err := cfg.Signing.GetPrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing key: %w", err)
+	}
+	activeKey, err := keyService.EnsureActiveKey(context.Background(), seedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize signing key: %w", err)
+	}
+	logger.Infof("Active signing key: %s", activeKey.KeyID)
 
 	// Initialize handlers
 	appHandler := handler.NewApplicationHandler(appService, logger)
 	licenseHandler := handler.NewLicenseHandler(licenseService, logger)
 	clientHandler := handler.NewClientHandler(clientService, logger)
+	keyHandler := handler.NewKeyHandler(keyService, logger)
+	featureHandler := handler.NewFeatureHandler(featureService, logger)
+	licenseTypeHandler := handler.NewLicenseTypeHandler(licenseTypeService, logger)
+	riskHandler := handler.NewRiskHandler(anomalyService, logger)
+	webhookHandler := handler.NewWebhookHandler(webhookService, logger)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService, logger)
 
 	// Initialize middlewares
-	authMiddleware := middleware.NewAuthMiddleware(cfg.JWT.Secret)
+	legacyHMACUntil, err := cfg.JWT.GetLegacyHMACUntil()
+	if err != nil {
+		return nil, err
+	}
+	authMiddleware := middleware.NewAuthMiddleware(cfg.JWT.Secret, legacyHMACUntil, keyService)
+	adminMiddleware := middleware.NewAdminMiddleware(cfg.Admin.Token)
 	corsMiddleware := middleware.NewCORSMiddleware(cfg.Server.AllowedOrigins)
 
 	// Setup routes
-	setupRoutes(router, *authMiddleware, *corsMiddleware, appHandler, licenseHandler, clientHandler)
+	setupRoutes(
+		router, *authMiddleware, *adminMiddleware, *corsMiddleware,
+		appHandler, licenseHandler, clientHandler, keyHandler, featureHandler, licenseTypeHandler, riskHandler,
+		webhookHandler, subscriptionHandler,
+	)
 
 	// Create HTTP server
 	httpServer := &http.Server{
@@ -83,16 +184,130 @@ func NewApp(cfg *config.Config, logger *zap.SugaredLogger) (*App, error) {
 		MaxHeaderBytes: cfg.Server.MaxHeaderBytes,
 	}
 
+	// Register background jobs
+	jobs := []job{
+		{
+			name:     "lease-expiry",
+			interval: cfg.Jobs.LeaseExpiryInterval,
+			run: func(ctx context.Context) error {
+				expired, err := licenseService.ExpireLeases(ctx)
+				if err == nil && expired > 0 {
+					logger.Infof("Expired %d stale license leases", expired)
+				}
+				return err
+			},
+		},
+		{
+			name:     "license-reinstatement",
+			interval: cfg.Jobs.ReinstatementInterval,
+			run: func(ctx context.Context) error {
+				reinstated, err := licenseService.ReinstateDue(ctx)
+				if reinstated > 0 {
+					logger.Infof("Reinstated %d suspended licenses", reinstated)
+				}
+				return err
+			},
+		},
+		{
+			name:     "usage-reset",
+			interval: cfg.Jobs.UsageResetInterval,
+			run: func(ctx context.Context) error {
+				reset, err := licenseService.ResetUsage(ctx)
+				if reset > 0 {
+					logger.Infof("Reset %d usage counters", reset)
+				}
+				return err
+			},
+		},
+		{
+			name:     "anomaly-detection",
+			interval: cfg.Jobs.AnomalyInterval,
+			run: func(ctx context.Context) error {
+				flagged, err := anomalyService.AnalyzeAll(ctx)
+				if flagged > 0 {
+					logger.Infof("Flagged %d licenses for suspected key sharing", flagged)
+				}
+				return err
+			},
+		},
+		{
+			name:     "license-expiration",
+			interval: cfg.Jobs.ExpirationInterval,
+			run: func(ctx context.Context) error {
+				expired, err := licenseService.ExpireLicenses(ctx)
+				if err == nil && expired > 0 {
+					logger.Infof("Deactivated %d expired licenses", expired)
+				}
+				return err
+			},
+		},
+		{
+			name:     "subscription-periods",
+			interval: cfg.Jobs.SubscriptionInterval,
+			run: func(ctx context.Context) error {
+				settled, err := subscriptionService.ProcessPeriodEnds(ctx)
+				if settled > 0 {
+					logger.Infof("Settled %d ended subscription periods", settled)
+				}
+				return err
+			},
+		},
+		{
+			name:     "outbox-dispatch",
+			interval: cfg.Jobs.OutboxInterval,
+			run: func(ctx context.Context) error {
+				_, err := outboxService.Dispatch(ctx)
+				return err
+			},
+		},
+		{
+			name:     "outbox-cleanup",
+			interval: cfg.Jobs.OutboxCleanupInterval,
+			run: func(ctx context.Context) error {
+				deleted, err := outboxService.Cleanup(ctx)
+				if deleted > 0 {
+					logger.Infof("Deleted %d published outbox events", deleted)
+				}
+				return err
+			},
+		},
+		{
+			name:     "webhook-delivery",
+			interval: cfg.Jobs.WebhookInterval,
+			run: func(ctx context.Context) error {
+				_, err := webhookService.DeliverDue(ctx)
+				return err
+			},
+		},
+		{
+			name:     "expiry-notices",
+			interval: cfg.Jobs.ExpiryNoticeInterval,
+			run: func(ctx context.Context) error {
+				sent, err := licenseService.NotifyExpiring(ctx, cfg.Notices.ExpiryWindows)
+				if sent > 0 {
+					logger.Infof("Sent %d license expiry notices", sent)
+				}
+				return err
+			},
+		},
+	}
+
 	return &App{
 		config:     cfg,
 		logger:     logger,
 		db:         db,
 		router:     router,
 		httpServer: httpServer,
+		jobs:       jobs,
 	}, nil
 }
 
 func (a *App) Start() error {
+	// Start background jobs
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	a.startJobs(jobsCtx)
+
 	// Start server in a goroutine
 	go func() {
 		if err := a.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -118,6 +333,10 @@ func (a *App) Start() error {
 		return fmt.Errorf("server forced to shutdown: %w", err)
 	}
 
+	// Stop background jobs
+	stopJobs()
+	a.jobsWG.Wait()
+
 	// Close database connection
 	if err := postgres.CloseConnection(a.db); err != nil {
 		return fmt.Errorf("error closing database connection: %w", err)
@@ -129,13 +348,21 @@ func (a *App) Start() error {
 func setupRoutes(
 	r *gin.Engine,
 	auth middleware.AuthMiddleware,
+	admin middleware.AdminMiddleware,
 	cors middleware.CORSMiddleware,
 	appHandler *handler.ApplicationHandler,
 	licenseHandler *handler.LicenseHandler,
 	clientHandler *handler.ClientHandler,
+	keyHandler *handler.KeyHandler,
+	featureHandler *handler.FeatureHandler,
+	licenseTypeHandler *handler.LicenseTypeHandler,
+	riskHandler *handler.RiskHandler,
+	webhookHandler *handler.WebhookHandler,
+	subscriptionHandler *handler.SubscriptionHandler,
 ) {
 	// Apply global middlewares
 	r.Use(cors.Handler())
+	r.Use(middleware.RequestMetadata())
 	r.Use(middleware.RequestLogger())
 
 	// Health check
@@ -148,6 +375,20 @@ func setupRoutes(
 	{
 		// Public routes
 		v1.POST("/auth/token", appHandler.GenerateToken)
+		v1.GET("/keys", keyHandler.PublicKeys)
+
+		// Operator routes
+		operator := v1.Group("/admin")
+		operator.Use(admin.Handler())
+		{
+			// Signing key administration routes
+			keys := operator.Group("/keys")
+			{
+				keys.GET("", keyHandler.List)
+				keys.POST("/rotate", keyHandler.Rotate)
+				keys.POST("/:kid/retire", keyHandler.Retire)
+			}
+		}
 
 		// Protected routes
 		authorized := v1.Group("")
@@ -163,17 +404,89 @@ func setupRoutes(
 				apps.DELETE("/:id", appHandler.Delete)
 			}
 
+			// Feature catalog routes
+			features := authorized.Group("/features")
+			{
+				features.POST("", featureHandler.Create)
+				features.GET("", featureHandler.List)
+				features.GET("/:id", featureHandler.Get)
+				features.PUT("/:id", featureHandler.Update)
+				features.DELETE("/:id", featureHandler.Delete)
+			}
+
+			// License type routes
+			licenseTypes := authorized.Group("/license-types")
+			{
+				licenseTypes.POST("", licenseTypeHandler.Create)
+				licenseTypes.GET("", licenseTypeHandler.List)
+				licenseTypes.GET("/:id", licenseTypeHandler.Get)
+				licenseTypes.PUT("/:id", licenseTypeHandler.Update)
+				licenseTypes.DELETE("/:id", licenseTypeHandler.Delete)
+			}
+
 			// License routes
 			licenses := authorized.Group("/licenses")
 			{
 				licenses.POST("", licenseHandler.Create)
 				licenses.GET("", licenseHandler.List)
 				licenses.GET("/:id", licenseHandler.Get)
+				licenses.GET("/:id/file", licenseHandler.GetFile)
 				licenses.PUT("/:id", licenseHandler.Update)
 				licenses.POST("/:id/revoke", licenseHandler.Revoke)
+				licenses.POST("/:id/renew", licenseHandler.Renew)
+				licenses.POST("/:id/convert", licenseHandler.ConvertTrial)
+				licenses.POST("/:id/change-plan", licenseHandler.ChangePlan)
+				licenses.PATCH("/:id/features", licenseHandler.UpdateFeatures)
+				licenses.POST("/:id/addons", licenseHandler.AttachAddon)
+				licenses.POST("/:id/addons/:addon_id/renew", licenseHandler.RenewAddon)
+				licenses.DELETE("/:id/addons/:addon_id", licenseHandler.DetachAddon)
+				licenses.POST("/:id/transfer", licenseHandler.Transfer)
+				licenses.POST("/transfer", licenseHandler.TransferClientLicenses)
+				licenses.POST("/bulk", licenseHandler.BulkCreate)
+				licenses.POST("/usage", licenseHandler.RecordUsage)
+				licenses.POST("/:id/suspend", licenseHandler.Suspend)
+				licenses.POST("/:id/reinstate", licenseHandler.Reinstate)
 				licenses.POST("/:id/validate", licenseHandler.Validate)
+				licenses.GET("/:id/activations", licenseHandler.ListActivations)
+				licenses.GET("/:id/activities", licenseHandler.ListActivities)
+				licenses.GET("/:id/risk", riskHandler.Get)
+				licenses.POST("/:id/risk", riskHandler.Analyze)
+				licenses.POST("/activate", licenseHandler.Activate)
+				licenses.POST("/deactivate", licenseHandler.Deactivate)
+				licenses.GET("/:id/leases", licenseHandler.ListLeases)
+				licenses.GET("/:id/usage/history", licenseHandler.UsageHistory)
+				licenses.POST("/leases", licenseHandler.CheckoutLease)
+				licenses.POST("/leases/:lease_id/heartbeat", licenseHandler.HeartbeatLease)
+				licenses.DELETE("/leases/:lease_id", licenseHandler.ReleaseLease)
 			}
 
+			// Webhook routes
+			webhooks := authorized.Group("/webhooks")
+			{
+				webhooks.POST("", webhookHandler.Create)
+				webhooks.GET("", webhookHandler.List)
+				webhooks.GET("/:id", webhookHandler.Get)
+				webhooks.PUT("/:id", webhookHandler.Update)
+				webhooks.DELETE("/:id", webhookHandler.Delete)
+				webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+				webhooks.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+			}
+
+			// Subscription routes
+			subscriptions := authorized.Group("/subscriptions")
+			{
+				subscriptions.POST("", subscriptionHandler.Create)
+				subscriptions.GET("", subscriptionHandler.List)
+				subscriptions.GET("/:id", subscriptionHandler.Get)
+				subscriptions.POST("/:id/renew", subscriptionHandler.Renew)
+				subscriptions.POST("/:id/past-due", subscriptionHandler.MarkPastDue)
+				subscriptions.POST("/:id/cancel", subscriptionHandler.Cancel)
+				subscriptions.POST("/:id/resume", subscriptionHandler.Resume)
+			}
+
+			// Key sharing risk routes
+			authorized.GET("/risk-reports", riskHandler.List)
+
 			// Client routes
 			clients := authorized.Group("/clients")
 			{
@@ -1,16 +1,20 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/service"
 )
 
 type Response struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 type BaseHandler struct {
@@ -37,6 +41,30 @@ func (h *BaseHandler) error(c *gin.Context, status int, err error) {
 	})
 }
 
+// invalid reports an input error, with field-level details when available
+func (h *BaseHandler) invalid(c *gin.Context, err error) {
+	response := Response{
+		Success: false,
+		Error:   err.Error(),
+	}
+
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		response.Details = validationErr.Fields
+	}
+
+	c.JSON(http.StatusBadRequest, response)
+}
+
+// failure reports an error together with data describing it
+func (h *BaseHandler) failure(c *gin.Context, status int, err error, data interface{}) {
+	c.JSON(status, Response{
+		Success: false,
+		Data:    data,
+		Error:   err.Error(),
+	})
+}
+
 func (h *BaseHandler) created(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusCreated, Response{
 		Success: true,
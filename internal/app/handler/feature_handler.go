@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/service"
+)
+
+type FeatureHandler struct {
+	BaseHandler
+	service service.FeatureService
+}
+
+func NewFeatureHandler(service service.FeatureService, logger *zap.SugaredLogger) *FeatureHandler {
+	return &FeatureHandler{
+		BaseHandler: NewBaseHandler(logger),
+		service:     service,
+	}
+}
+
+func (h *FeatureHandler) Create(c *gin.Context) {
+	var feature models.FeatureDefinition
+	if err := c.ShouldBindJSON(&feature); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get application ID from context (set by auth middleware)
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+	feature.ApplicationID = appID.(uuid.UUID)
+
+	createdFeature, err := h.service.Create(c.Request.Context(), &feature)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDuplicateFeature):
+			h.error(c, http.StatusConflict, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.invalid(c, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.created(c, createdFeature)
+}
+
+func (h *FeatureHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid feature ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	feature, err := h.service.GetByID(c.Request.Context(), appID.(uuid.UUID), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, feature)
+}
+
+func (h *FeatureHandler) List(c *gin.Context) {
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	features, err := h.service.List(c.Request.Context(), appID.(uuid.UUID))
+	if err != nil {
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, features)
+}
+
+func (h *FeatureHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid feature ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	var feature models.FeatureDefinition
+	if err := c.ShouldBindJSON(&feature); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	feature.ID = id
+	feature.ApplicationID = appID.(uuid.UUID)
+
+	updatedFeature, err := h.service.Update(c.Request.Context(), &feature)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.invalid(c, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, updatedFeature)
+}
+
+func (h *FeatureHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid feature ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), appID.(uuid.UUID), id); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.noContent(c)
+}
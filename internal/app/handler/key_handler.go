@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/service"
+)
+
+// JSONWebKey is the public representation of a signing key (RFC 8037 OKP key)
+type JSONWebKey struct {
+	KeyID     string    `json:"kid"`
+	KeyType   string    `json:"kty"`
+	Curve     string    `json:"crv"`
+	Algorithm string    `json:"alg"`
+	Use       string    `json:"use"`
+	X         string    `json:"x"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type KeyHandler struct {
+	BaseHandler
+	service service.KeyService
+}
+
+func NewKeyHandler(service service.KeyService, logger *zap.SugaredLogger) *KeyHandler {
+	return &KeyHandler{
+		BaseHandler: NewBaseHandler(logger),
+		service:     service,
+	}
+}
+
+// PublicKeys returns the keys clients may use to verify tokens and license files
+func (h *KeyHandler) PublicKeys(c *gin.Context) {
+	keys, err := h.service.VerificationKeys(c.Request.Context())
+	if err != nil {
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	jwks := make([]JSONWebKey, 0, len(keys))
+	for _, key := range keys {
+		jwk, err := toJSONWebKey(key)
+		if err != nil {
+			h.logger.Warnf("Skipping signing key %s: %v", key.KeyID, err)
+			continue
+		}
+		jwks = append(jwks, jwk)
+	}
+
+	h.success(c, gin.H{"keys": jwks})
+}
+
+func (h *KeyHandler) List(c *gin.Context) {
+	keys, err := h.service.List(c.Request.Context())
+	if err != nil {
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, keys)
+}
+
+func (h *KeyHandler) Rotate(c *gin.Context) {
+	key, err := h.service.Rotate(c.Request.Context())
+	if err != nil {
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.created(c, key)
+}
+
+func (h *KeyHandler) Retire(c *gin.Context) {
+	if err := h.service.Retire(c.Request.Context(), c.Param("kid")); err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrInvalidStatus):
+			h.error(c, http.StatusConflict, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.noContent(c)
+}
+
+// Helper functions
+
+func toJSONWebKey(key models.SigningKey) (JSONWebKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(key.PublicKey)
+	if err != nil {
+		return JSONWebKey{}, err
+	}
+
+	return JSONWebKey{
+		KeyID:     key.KeyID,
+		KeyType:   "OKP",
+		Curve:     "Ed25519",
+		Algorithm: key.Algorithm,
+		Use:       "sig",
+		X:         base64.RawURLEncoding.EncodeToString(raw),
+		Status:    key.Status,
+		CreatedAt: key.CreatedAt,
+	}, nil
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -33,6 +34,10 @@ func (h *LicenseHandler) Create(c *gin.Context) {
 
 	createdLicense, err := h.service.Create(c.Request.Context(), &license)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			h.invalid(c, err)
+			return
+		}
 		h.error(c, http.StatusInternalServerError, err)
 		return
 	}
@@ -40,6 +45,70 @@ func (h *LicenseHandler) Create(c *gin.Context) {
 	h.created(c, createdLicense)
 }
 
+// BulkCreate issues licenses from a JSON array of rows or a CSV upload. The
+// mode query parameter selects atomic (default) or partial issuance.
+func (h *LicenseHandler) BulkCreate(c *gin.Context) {
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	var rows []service.BulkIssueRow
+	switch c.ContentType() {
+	case "multipart/form-data":
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			h.error(c, http.StatusBadRequest, errors.New("CSV file is required"))
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			h.error(c, http.StatusBadRequest, err)
+			return
+		}
+		defer file.Close()
+
+		if rows, err = service.ParseBulkIssueCSV(file); err != nil {
+			h.error(c, http.StatusBadRequest, err)
+			return
+		}
+	case "text/csv":
+		var err error
+		if rows, err = service.ParseBulkIssueCSV(c.Request.Body); err != nil {
+			h.error(c, http.StatusBadRequest, err)
+			return
+		}
+	default:
+		if err := c.ShouldBindJSON(&rows); err != nil {
+			h.error(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	result, err := h.service.BulkIssue(c.Request.Context(), service.BulkIssueRequest{
+		ApplicationID: appID.(uuid.UUID),
+		Mode:          c.DefaultQuery("mode", service.BulkModeAtomic),
+		Rows:          rows,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			h.error(c, http.StatusBadRequest, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	if result.Created == 0 {
+		h.failure(c, http.StatusUnprocessableEntity, errors.New("no licenses were issued"), result)
+		return
+	}
+
+	h.created(c, result)
+}
+
 func (h *LicenseHandler) Get(c *gin.Context) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -60,6 +129,29 @@ func (h *LicenseHandler) Get(c *gin.Context) {
 	h.success(c, license)
 }
 
+func (h *LicenseHandler) GetFile(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	licenseFile, err := h.service.GetLicenseFile(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrSigningKeyUnavailable):
+			h.error(c, http.StatusServiceUnavailable, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, gin.H{"license_file": licenseFile})
+}
+
 func (h *LicenseHandler) List(c *gin.Context) {
 	var filters service.LicenseFilters
 	if err := c.ShouldBindQuery(&filters); err != nil {
@@ -92,11 +184,14 @@ func (h *LicenseHandler) Update(c *gin.Context) {
 
 	updatedLicense, err := h.service.Update(c.Request.Context(), &license)
 	if err != nil {
-		if errors.Is(err, service.ErrNotFound) {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
 			h.error(c, http.StatusNotFound, err)
-			return
+		case errors.Is(err, service.ErrInvalidInput):
+			h.invalid(c, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
 		}
-		h.error(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -130,24 +225,653 @@ func (h *LicenseHandler) Revoke(c *gin.Context) {
 	h.noContent(c)
 }
 
+func (h *LicenseHandler) Renew(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	// An empty body renews by the license type duration
+	var req struct {
+		Days int `json:"days"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.error(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	license, err := h.service.Renew(c.Request.Context(), id, req.Days)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrLicenseRevoked):
+			h.error(c, http.StatusConflict, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.error(c, http.StatusBadRequest, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, license)
+}
+
+func (h *LicenseHandler) ConvertTrial(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	var req struct {
+		LicenseTypeID uuid.UUID `json:"license_type_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	license, err := h.service.ConvertTrial(c.Request.Context(), id, req.LicenseTypeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrLicenseRevoked), errors.Is(err, service.ErrLicenseNotTrial):
+			h.error(c, http.StatusConflict, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.error(c, http.StatusBadRequest, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, license)
+}
+
+func (h *LicenseHandler) ChangePlan(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	var req service.PlanChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	change, err := h.service.ChangePlan(c.Request.Context(), id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrLicenseRevoked):
+			h.error(c, http.StatusConflict, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.error(c, http.StatusBadRequest, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, change)
+}
+
+func (h *LicenseHandler) Transfer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	var req service.TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+	req.ApplicationID = appID.(uuid.UUID)
+
+	license, err := h.service.Transfer(c.Request.Context(), id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.error(c, http.StatusBadRequest, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, license)
+}
+
+func (h *LicenseHandler) TransferClientLicenses(c *gin.Context) {
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	var req struct {
+		service.TransferRequest
+		FromClientID uuid.UUID `json:"from_client_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+	req.ApplicationID = appID.(uuid.UUID)
+
+	licenses, err := h.service.TransferClientLicenses(c.Request.Context(), req.FromClientID, req.TransferRequest)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.error(c, http.StatusBadRequest, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, licenses)
+}
+
+func (h *LicenseHandler) UpdateFeatures(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	var req struct {
+		Operations []service.FeatureOperation `json:"operations" binding:"required,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	license, err := h.service.UpdateFeatures(c.Request.Context(), id, req.Operations)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.invalid(c, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, license)
+}
+
+func (h *LicenseHandler) Suspend(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	var req struct {
+		Reason      string     `json:"reason" binding:"required"`
+		ReinstateAt *time.Time `json:"reinstate_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	license, err := h.service.Suspend(c.Request.Context(), id, req.Reason, req.ReinstateAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrLicenseRevoked):
+			h.error(c, http.StatusConflict, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.error(c, http.StatusBadRequest, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, license)
+}
+
+func (h *LicenseHandler) Reinstate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	license, err := h.service.Reinstate(c.Request.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrLicenseNotSuspended):
+			h.error(c, http.StatusConflict, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, license)
+}
+
 func (h *LicenseHandler) Validate(c *gin.Context) {
 	var req struct {
-		LicenseKey string `json:"license_key" binding:"required"`
+		LicenseKey  string `json:"license_key" binding:"required"`
+		Fingerprint string `json:"machine_fingerprint"`
+		Version     string `json:"version"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	opts := service.ValidateOptions{
+		Fingerprint: req.Fingerprint,
+		Version:     req.Version,
+	}
+	if appID, exists := c.Get("application_id"); exists {
+		opts.ApplicationID = appID.(uuid.UUID)
+	}
+
+	validationResult, err := h.service.Validate(c.Request.Context(), req.LicenseKey, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLicenseInvalid):
+			h.error(c, http.StatusUnauthorized, err)
+		case errors.Is(err, service.ErrMachineNotActivated), errors.Is(err, service.ErrLicenseSuspended):
+			h.error(c, http.StatusForbidden, err)
+		case errors.Is(err, service.ErrVersionNotAllowed):
+			h.failure(c, http.StatusForbidden, err, validationResult)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.error(c, http.StatusBadRequest, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, validationResult)
+}
+
+func (h *LicenseHandler) RecordUsage(c *gin.Context) {
+	var req service.UsageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+	if appID, exists := c.Get("application_id"); exists {
+		req.ApplicationID = appID.(uuid.UUID)
+	}
+
+	usage, err := h.service.RecordUsage(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLicenseUsageLimitExceeded):
+			h.failure(c, http.StatusForbidden, err, usage)
+		case errors.Is(err, service.ErrLicenseInvalid):
+			h.error(c, http.StatusUnauthorized, err)
+		case errors.Is(err, service.ErrLicenseExpired), errors.Is(err, service.ErrLicenseRevoked),
+			errors.Is(err, service.ErrLicenseSuspended):
+			h.error(c, http.StatusForbidden, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.error(c, http.StatusBadRequest, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, usage)
+}
+
+func (h *LicenseHandler) UsageHistory(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	history, err := h.service.UsageHistory(c.Request.Context(), id, c.Query("metric"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, history)
+}
+
+func (h *LicenseHandler) Activate(c *gin.Context) {
+	var req service.ActivationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+	req.IPAddress = c.ClientIP()
+	if appID, exists := c.Get("application_id"); exists {
+		req.ApplicationID = appID.(uuid.UUID)
+	}
+
+	activation, err := h.service.Activate(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLicenseInvalid):
+			h.error(c, http.StatusUnauthorized, err)
+		case errors.Is(err, service.ErrLicenseExpired), errors.Is(err, service.ErrLicenseRevoked),
+			errors.Is(err, service.ErrLicenseSuspended):
+			h.error(c, http.StatusForbidden, err)
+		case errors.Is(err, service.ErrActivationLimitReached):
+			h.error(c, http.StatusConflict, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.error(c, http.StatusBadRequest, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, activation)
+}
+
+func (h *LicenseHandler) Deactivate(c *gin.Context) {
+	var req struct {
+		LicenseKey         string `json:"license_key" binding:"required"`
+		MachineFingerprint string `json:"machine_fingerprint" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.error(c, http.StatusBadRequest, err)
 		return
 	}
 
-	validationResult, err := h.service.Validate(c.Request.Context(), req.LicenseKey)
+	var applicationID uuid.UUID
+	if appID, exists := c.Get("application_id"); exists {
+		applicationID = appID.(uuid.UUID)
+	}
+
+	if err := h.service.Deactivate(c.Request.Context(), applicationID, req.LicenseKey, req.MachineFingerprint); err != nil {
+		switch {
+		case errors.Is(err, service.ErrLicenseInvalid):
+			h.error(c, http.StatusUnauthorized, err)
+		case errors.Is(err, service.ErrMachineNotActivated):
+			h.error(c, http.StatusNotFound, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.noContent(c)
+}
+
+func (h *LicenseHandler) ListActivations(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	activations, err := h.service.ListActivations(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, activations)
+}
+
+func (h *LicenseHandler) ListActivities(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	var filters service.ActivityFilters
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	activities, err := h.service.ListActivities(c.Request.Context(), id, filters)
 	if err != nil {
-		if errors.Is(err, service.ErrLicenseInvalid) {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			h.error(c, http.StatusBadRequest, err)
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, activities)
+}
+
+func (h *LicenseHandler) CheckoutLease(c *gin.Context) {
+	var req service.LeaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+	req.IPAddress = c.ClientIP()
+	if appID, exists := c.Get("application_id"); exists {
+		req.ApplicationID = appID.(uuid.UUID)
+	}
+
+	lease, err := h.service.CheckoutLease(c.Request.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLicenseInvalid):
 			h.error(c, http.StatusUnauthorized, err)
+		case errors.Is(err, service.ErrLicenseExpired), errors.Is(err, service.ErrLicenseRevoked),
+			errors.Is(err, service.ErrLicenseSuspended), errors.Is(err, service.ErrLicenseNotFloating):
+			h.error(c, http.StatusForbidden, err)
+		case errors.Is(err, service.ErrNoLeaseAvailable):
+			h.error(c, http.StatusConflict, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.error(c, http.StatusBadRequest, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.created(c, lease)
+}
+
+func (h *LicenseHandler) HeartbeatLease(c *gin.Context) {
+	leaseID, err := uuid.Parse(c.Param("lease_id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid lease ID"))
+		return
+	}
+
+	lease, err := h.service.HeartbeatLease(c.Request.Context(), leaseID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLeaseNotFound):
+			h.error(c, http.StatusGone, err)
+		case errors.Is(err, service.ErrLicenseInvalid), errors.Is(err, service.ErrLicenseExpired),
+			errors.Is(err, service.ErrLicenseRevoked), errors.Is(err, service.ErrLicenseSuspended):
+			h.error(c, http.StatusForbidden, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, lease)
+}
+
+func (h *LicenseHandler) ReleaseLease(c *gin.Context) {
+	leaseID, err := uuid.Parse(c.Param("lease_id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid lease ID"))
+		return
+	}
+
+	if err := h.service.ReleaseLease(c.Request.Context(), leaseID); err != nil {
+		if errors.Is(err, service.ErrLeaseNotFound) {
+			h.error(c, http.StatusNotFound, err)
 			return
 		}
 		h.error(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	h.success(c, validationResult)
+	h.noContent(c)
+}
+
+func (h *LicenseHandler) ListLeases(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	leases, err := h.service.ListLeases(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, leases)
+}
+
+func (h *LicenseHandler) AttachAddon(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	var req service.AddonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	addon, err := h.service.AttachAddon(c.Request.Context(), id, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrLicenseRevoked), errors.Is(err, service.ErrDuplicateAddon):
+			h.error(c, http.StatusConflict, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.invalid(c, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.created(c, addon)
+}
+
+func (h *LicenseHandler) RenewAddon(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+	addonID, err := uuid.Parse(c.Param("addon_id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid add-on ID"))
+		return
+	}
+
+	// An empty body renews by the add-on type duration
+	var req struct {
+		Days int `json:"days"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.error(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	addon, err := h.service.RenewAddon(c.Request.Context(), id, addonID, req.Days)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrLicenseRevoked):
+			h.error(c, http.StatusConflict, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.error(c, http.StatusBadRequest, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, addon)
+}
+
+func (h *LicenseHandler) DetachAddon(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+	addonID, err := uuid.Parse(c.Param("addon_id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid add-on ID"))
+		return
+	}
+
+	if err := h.service.DetachAddon(c.Request.Context(), id, addonID); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.noContent(c)
 }
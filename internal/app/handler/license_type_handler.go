@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/service"
+)
+
+type LicenseTypeHandler struct {
+	BaseHandler
+	service service.LicenseTypeService
+}
+
+func NewLicenseTypeHandler(service service.LicenseTypeService, logger *zap.SugaredLogger) *LicenseTypeHandler {
+	return &LicenseTypeHandler{
+		BaseHandler: NewBaseHandler(logger),
+		service:     service,
+	}
+}
+
+func (h *LicenseTypeHandler) Create(c *gin.Context) {
+	var licenseType models.LicenseType
+	if err := c.ShouldBindJSON(&licenseType); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get application ID from context (set by auth middleware)
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+	licenseType.ApplicationID = appID.(uuid.UUID)
+
+	createdType, err := h.service.Create(c.Request.Context(), &licenseType)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			h.invalid(c, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.created(c, createdType)
+}
+
+func (h *LicenseTypeHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license type ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	licenseType, err := h.service.GetByID(c.Request.Context(), appID.(uuid.UUID), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, licenseType)
+}
+
+func (h *LicenseTypeHandler) List(c *gin.Context) {
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	licenseTypes, err := h.service.List(c.Request.Context(), appID.(uuid.UUID))
+	if err != nil {
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, licenseTypes)
+}
+
+func (h *LicenseTypeHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license type ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	var licenseType models.LicenseType
+	if err := c.ShouldBindJSON(&licenseType); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	licenseType.ID = id
+	licenseType.ApplicationID = appID.(uuid.UUID)
+
+	updatedType, err := h.service.Update(c.Request.Context(), &licenseType)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.invalid(c, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, updatedType)
+}
+
+func (h *LicenseTypeHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license type ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), appID.(uuid.UUID), id); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.noContent(c)
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/service"
+)
+
+type RiskHandler struct {
+	BaseHandler
+	service service.AnomalyService
+}
+
+func NewRiskHandler(service service.AnomalyService, logger *zap.SugaredLogger) *RiskHandler {
+	return &RiskHandler{
+		BaseHandler: NewBaseHandler(logger),
+		service:     service,
+	}
+}
+
+func (h *RiskHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	report, err := h.service.GetReport(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, report)
+}
+
+func (h *RiskHandler) Analyze(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid license ID"))
+		return
+	}
+
+	report, err := h.service.Analyze(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, report)
+}
+
+func (h *RiskHandler) List(c *gin.Context) {
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	// Only flagged licenses by default
+	minScore := 1
+	if raw := c.Query("min_score"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			h.error(c, http.StatusBadRequest, errors.New("invalid min_score"))
+			return
+		}
+		minScore = value
+	}
+
+	reports, err := h.service.List(c.Request.Context(), appID.(uuid.UUID), minScore)
+	if err != nil {
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, reports)
+}
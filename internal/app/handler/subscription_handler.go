@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/service"
+)
+
+type SubscriptionHandler struct {
+	BaseHandler
+	service service.SubscriptionService
+}
+
+func NewSubscriptionHandler(service service.SubscriptionService, logger *zap.SugaredLogger) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		BaseHandler: NewBaseHandler(logger),
+		service:     service,
+	}
+}
+
+func (h *SubscriptionHandler) Create(c *gin.Context) {
+	var req service.SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get application ID from context (set by auth middleware)
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	subscription, err := h.service.Create(c.Request.Context(), appID.(uuid.UUID), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrLicenseRevoked), errors.Is(err, service.ErrDuplicateSubscription):
+			h.error(c, http.StatusConflict, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.invalid(c, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.created(c, subscription)
+}
+
+func (h *SubscriptionHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid subscription ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	subscription, err := h.service.GetByID(c.Request.Context(), appID.(uuid.UUID), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, subscription)
+}
+
+func (h *SubscriptionHandler) List(c *gin.Context) {
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	subscriptions, err := h.service.List(c.Request.Context(), appID.(uuid.UUID), c.Query("status"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			h.error(c, http.StatusBadRequest, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, subscriptions)
+}
+
+func (h *SubscriptionHandler) Renew(c *gin.Context) {
+	h.transition(c, h.service.Renew)
+}
+
+func (h *SubscriptionHandler) MarkPastDue(c *gin.Context) {
+	h.transition(c, h.service.MarkPastDue)
+}
+
+func (h *SubscriptionHandler) Resume(c *gin.Context) {
+	h.transition(c, h.service.Resume)
+}
+
+// Cancel ends the subscription right away, or at the end of the current
+// period when at_period_end is set
+func (h *SubscriptionHandler) Cancel(c *gin.Context) {
+	var req struct {
+		AtPeriodEnd bool `json:"at_period_end"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.error(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	h.transition(c, func(ctx context.Context, applicationID, id uuid.UUID) (*models.Subscription, error) {
+		return h.service.Cancel(ctx, applicationID, id, req.AtPeriodEnd)
+	})
+}
+
+// transition runs a subscription state change and maps its errors
+func (h *SubscriptionHandler) transition(
+	c *gin.Context,
+	change func(ctx context.Context, applicationID, id uuid.UUID) (*models.Subscription, error),
+) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid subscription ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	subscription, err := change(c.Request.Context(), appID.(uuid.UUID), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrSubscriptionCanceled),
+			errors.Is(err, service.ErrLicenseRevoked),
+			errors.Is(err, service.ErrInvalidStatus):
+			h.error(c, http.StatusConflict, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, subscription)
+}
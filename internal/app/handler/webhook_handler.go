@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/service"
+)
+
+type WebhookHandler struct {
+	BaseHandler
+	service service.WebhookService
+}
+
+func NewWebhookHandler(service service.WebhookService, logger *zap.SugaredLogger) *WebhookHandler {
+	return &WebhookHandler{
+		BaseHandler: NewBaseHandler(logger),
+		service:     service,
+	}
+}
+
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var endpoint models.WebhookEndpoint
+	if err := c.ShouldBindJSON(&endpoint); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// Get application ID from context (set by auth middleware)
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+	endpoint.ApplicationID = appID.(uuid.UUID)
+
+	createdEndpoint, err := h.service.Create(c.Request.Context(), &endpoint)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			h.invalid(c, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.created(c, createdEndpoint)
+}
+
+func (h *WebhookHandler) Get(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid webhook ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	endpoint, err := h.service.GetByID(c.Request.Context(), appID.(uuid.UUID), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, endpoint)
+}
+
+func (h *WebhookHandler) List(c *gin.Context) {
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	endpoints, err := h.service.List(c.Request.Context(), appID.(uuid.UUID))
+	if err != nil {
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, endpoints)
+}
+
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid webhook ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	var endpoint models.WebhookEndpoint
+	if err := c.ShouldBindJSON(&endpoint); err != nil {
+		h.error(c, http.StatusBadRequest, err)
+		return
+	}
+
+	endpoint.ID = id
+	endpoint.ApplicationID = appID.(uuid.UUID)
+
+	updatedEndpoint, err := h.service.Update(c.Request.Context(), &endpoint)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			h.error(c, http.StatusNotFound, err)
+		case errors.Is(err, service.ErrInvalidInput):
+			h.invalid(c, err)
+		default:
+			h.error(c, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	h.success(c, updatedEndpoint)
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid webhook ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), appID.(uuid.UUID), id); err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.noContent(c)
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid webhook ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	deliveries, err := h.service.ListDeliveries(c.Request.Context(), appID.(uuid.UUID), id, c.Query("status"))
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.success(c, deliveries)
+}
+
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid webhook ID"))
+		return
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		h.error(c, http.StatusBadRequest, errors.New("invalid delivery ID"))
+		return
+	}
+
+	// Get application ID from context
+	appID, exists := c.Get("application_id")
+	if !exists {
+		h.error(c, http.StatusUnauthorized, errors.New("application ID not found in context"))
+		return
+	}
+
+	delivery, err := h.service.Redeliver(c.Request.Context(), appID.(uuid.UUID), id, deliveryID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			h.error(c, http.StatusNotFound, err)
+			return
+		}
+		h.error(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	h.created(c, delivery)
+}
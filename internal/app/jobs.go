@@ -0,0 +1,47 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/requestmeta"
+)
+
+// job is a background task run periodically while the server is up
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+// startJobs runs every registered job on its own ticker until ctx is cancelled
+func (a *App) startJobs(ctx context.Context) {
+	for _, j := range a.jobs {
+		if j.interval <= 0 {
+			a.logger.Warnf("Background job %s disabled: non-positive interval", j.name)
+			continue
+		}
+
+		a.jobsWG.Add(1)
+		go func(j job) {
+			defer a.jobsWG.Done()
+
+			// Activities recorded by the job are attributed to it
+			ctx := requestmeta.WithPrincipal(ctx, "job:"+j.name)
+
+			ticker := time.NewTicker(j.interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := j.run(ctx); err != nil && ctx.Err() == nil {
+						a.logger.Errorf("Background job %s failed: %v", j.name, err)
+					}
+				}
+			}
+		}(j)
+	}
+}
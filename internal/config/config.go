@@ -1,6 +1,8 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"time"
@@ -13,6 +15,13 @@ type Config struct {
 	Database DatabaseConfig
 	Server   ServerConfig
 	JWT      JWTConfig
+	Admin    AdminConfig
+	Signing  SigningConfig
+	Jobs     JobsConfig
+	Anomaly  AnomalyConfig
+	Notices  NoticesConfig
+	Webhooks WebhooksConfig
+	Outbox   OutboxConfig
 }
 
 type AppConfig struct {
@@ -38,11 +47,83 @@ type ServerConfig struct {
 	RequestTimeout time.Duration
 	MaxHeaderBytes int
 	AllowedOrigins []string
+	// TrustedProxies are the proxy addresses or CIDRs whose forwarding headers
+	// are believed when resolving the client IP; none are trusted by default
+	TrustedProxies []string
 }
 
 type JWTConfig struct {
 	Secret          string
 	ExpirationHours int
+	// LegacyHMACUntil is the date (YYYY-MM-DD) after which tokens signed with
+	// the shared secret are no longer accepted; empty rejects them outright
+	LegacyHMACUntil string
+}
+
+type AdminConfig struct {
+	// Token authenticates operators on the /admin routes, which are
+	// disabled while it is empty
+	Token string
+}
+
+type SigningConfig struct {
+	// PrivateKey is the base64-encoded Ed25519 seed or private key imported
+	// into the key store as the first signing key when the store is empty
+	PrivateKey string
+	// KeyEncryptionKey is the base64-encoded 32 byte AES key that encrypts
+	// signing keys at rest
+	KeyEncryptionKey string
+}
+
+type JobsConfig struct {
+	LeaseExpiryInterval   time.Duration
+	ReinstatementInterval time.Duration
+	UsageResetInterval    time.Duration
+	AnomalyInterval       time.Duration
+	ExpiryNoticeInterval  time.Duration
+	ExpirationInterval    time.Duration
+	WebhookInterval       time.Duration
+	OutboxInterval        time.Duration
+	OutboxCleanupInterval time.Duration
+	SubscriptionInterval  time.Duration
+}
+
+type WebhooksConfig struct {
+	Timeout     time.Duration
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+	BatchSize   int
+}
+
+type OutboxConfig struct {
+	BatchSize  int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Retention is how long published events are kept
+	Retention time.Duration
+}
+
+type NoticesConfig struct {
+	// ExpiryWindows are the days before expiry at which licenses are reported
+	// as expiring soon
+	ExpiryWindows []int
+}
+
+type AnomalyConfig struct {
+	Window                  time.Duration
+	MaxDistinctIPs          int
+	MaxDistinctFingerprints int
+	BurstWindow             time.Duration
+	BurstThreshold          int
+	// MaxTravelSpeed in km/h; faster moves between requests count as impossible travel
+	MaxTravelSpeed float64
+	// GeoIPFile is a CSV of network,latitude,longitude rows; impossible travel
+	// detection is disabled without it
+	GeoIPFile string
+	// AutoSuspendScore suspends licenses scoring at least this much, not
+	// counting IP based reasons; zero disables automatic suspension
+	AutoSuspendScore int
 }
 
 // LoadConfig reads configuration from environment variables
@@ -95,6 +176,7 @@ func setDefaults() {
 	viper.SetDefault("server.requestTimeout", "30s")
 	viper.SetDefault("server.maxHeaderBytes", 1<<20) // 1 MB
 	viper.SetDefault("server.allowedOrigins", []string{"*"})
+	viper.SetDefault("server.trustedProxies", []string{})
 
 	// Database defaults
 	viper.SetDefault("database.host", "localhost")
@@ -103,6 +185,44 @@ func setDefaults() {
 
 	// JWT defaults
 	viper.SetDefault("jwt.expirationHours", 24)
+	viper.SetDefault("jwt.legacyHMACUntil", "2027-01-01")
+
+	// Background job defaults
+	viper.SetDefault("jobs.leaseExpiryInterval", "30s")
+	viper.SetDefault("jobs.reinstatementInterval", "5m")
+	viper.SetDefault("jobs.usageResetInterval", "15m")
+	viper.SetDefault("jobs.anomalyInterval", "1h")
+	viper.SetDefault("jobs.expiryNoticeInterval", "1h")
+	viper.SetDefault("jobs.expirationInterval", "10m")
+	viper.SetDefault("jobs.webhookInterval", "5s")
+	viper.SetDefault("jobs.outboxInterval", "1s")
+	viper.SetDefault("jobs.outboxCleanupInterval", "1h")
+	viper.SetDefault("jobs.subscriptionInterval", "5m")
+
+	// Webhook defaults: retries back off from 30s to 6h, giving up after about a day
+	viper.SetDefault("webhooks.timeout", "10s")
+	viper.SetDefault("webhooks.maxAttempts", 14)
+	viper.SetDefault("webhooks.minBackoff", "30s")
+	viper.SetDefault("webhooks.maxBackoff", "6h")
+	viper.SetDefault("webhooks.batchSize", 50)
+
+	// Outbox defaults: failed events are retried every 5s up to every 5m
+	viper.SetDefault("outbox.batchSize", 100)
+	viper.SetDefault("outbox.minBackoff", "5s")
+	viper.SetDefault("outbox.maxBackoff", "5m")
+	viper.SetDefault("outbox.retention", "168h")
+
+	// Notice defaults
+	viper.SetDefault("notices.expiryWindows", []int{30, 7, 1})
+
+	// Key sharing detection defaults
+	viper.SetDefault("anomaly.window", "24h")
+	viper.SetDefault("anomaly.maxDistinctIPs", 10)
+	viper.SetDefault("anomaly.maxDistinctFingerprints", 5)
+	viper.SetDefault("anomaly.burstWindow", "1m")
+	viper.SetDefault("anomaly.burstThreshold", 60)
+	viper.SetDefault("anomaly.maxTravelSpeed", 1000)
+	viper.SetDefault("anomaly.autoSuspendScore", 0)
 }
 
 func validateConfig(config *Config) error {
@@ -121,6 +241,12 @@ func validateConfig(config *Config) error {
 	if config.JWT.Secret == "" {
 		return fmt.Errorf("JWT secret is required")
 	}
+	if _, err := config.JWT.GetLegacyHMACUntil(); err != nil {
+		return err
+	}
+	if _, err := config.Signing.GetKeyEncryptionKey(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -136,3 +262,54 @@ func (c *DatabaseConfig) GetDSN() string {
 func (c *ServerConfig) GetServerAddress() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
+
+// GetPrivateKey decodes the configured Ed25519 signing key.
+// It returns nil when no key is configured.
+func (c *SigningConfig) GetPrivateKey() (ed25519.PrivateKey, error) {
+	if c.PrivateKey == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(c.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key encoding: %w", err)
+	}
+
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	default:
+		return nil, fmt.Errorf("invalid signing key size: %d", len(raw))
+	}
+}
+
+// GetLegacyHMACUntil parses the legacy HMAC token deadline
+func (c *JWTConfig) GetLegacyHMACUntil() (time.Time, error) {
+	if c.LegacyHMACUntil == "" {
+		return time.Time{}, nil
+	}
+
+	until, err := time.Parse(time.DateOnly, c.LegacyHMACUntil)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid legacy HMAC deadline: %w", err)
+	}
+	return until, nil
+}
+
+// GetKeyEncryptionKey decodes the key encrypting signing keys at rest
+func (c *SigningConfig) GetKeyEncryptionKey() ([]byte, error) {
+	if c.KeyEncryptionKey == "" {
+		return nil, fmt.Errorf("signing key encryption key is required")
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(c.KeyEncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key encryption key encoding: %w", err)
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("invalid signing key encryption key size: %d", len(raw))
+	}
+	return raw, nil
+}
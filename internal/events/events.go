@@ -0,0 +1,156 @@
+// Package events defines the domain events emitted by the license manager and
+// the publishers that deliver them.
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Event types are named <subject>.<action>
+const (
+	LicenseCreated   = "license.created"
+	LicenseRenewed   = "license.renewed"
+	LicenseRevoked   = "license.revoked"
+	LicenseValidated = "license.validated"
+	// LicenseExpiringSoon is emitted once per notification window before a
+	// license expires
+	LicenseExpiringSoon = "license.expiring_soon"
+	LicenseSuspended    = "license.suspended"
+	LicenseReinstated   = "license.reinstated"
+	LicenseTransferred  = "license.transferred"
+	LicensePlanChanged  = "license.plan_changed"
+	// LicenseConverted is emitted when a trial is converted to a paid license
+	LicenseConverted = "license.converted"
+	// LicenseFeaturesUpdated is emitted when the feature overrides change
+	LicenseFeaturesUpdated = "license.features_updated"
+
+	ClientCreated = "client.created"
+
+	SubscriptionCreated  = "subscription.created"
+	SubscriptionRenewed  = "subscription.renewed"
+	SubscriptionUpdated  = "subscription.updated"
+	SubscriptionPastDue  = "subscription.past_due"
+	SubscriptionCanceled = "subscription.canceled"
+)
+
+// Types lists every event type, e.g. for validating subscriptions
+var Types = []string{
+	LicenseCreated,
+	LicenseRenewed,
+	LicenseRevoked,
+	LicenseValidated,
+	LicenseExpiringSoon,
+	LicenseSuspended,
+	LicenseReinstated,
+	LicenseTransferred,
+	LicensePlanChanged,
+	LicenseConverted,
+	LicenseFeaturesUpdated,
+	ClientCreated,
+	SubscriptionCreated,
+	SubscriptionRenewed,
+	SubscriptionUpdated,
+	SubscriptionPastDue,
+	SubscriptionCanceled,
+}
+
+// Event is something that happened to a license, client or subscription. The
+// subject is the entity named by the type prefix. Events may be delivered more
+// than once, so receivers should ignore IDs they have already seen.
+type Event struct {
+	ID            uuid.UUID      `json:"id"`
+	Type          string         `json:"type"`
+	ApplicationID uuid.UUID      `json:"application_id"`
+	SubjectID     uuid.UUID      `json:"subject_id"`
+	OccurredAt    time.Time      `json:"occurred_at"`
+	Data          map[string]any `json:"data"`
+}
+
+// New returns an event with a fresh ID
+func New(eventType string, applicationID, subjectID uuid.UUID, data map[string]any) Event {
+	return Event{
+		ID:            uuid.New(),
+		Type:          eventType,
+		ApplicationID: applicationID,
+		SubjectID:     subjectID,
+		OccurredAt:    time.Now(),
+		Data:          data,
+	}
+}
+
+// Publisher delivers events to interested parties
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// MultiPublisher delivers events to several publishers in turn, stopping at
+// the first error
+type MultiPublisher []Publisher
+
+func (m MultiPublisher) Publish(ctx context.Context, event Event) error {
+	for _, p := range m {
+		if err := p.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LogPublisher writes events to the log
+type LogPublisher struct {
+	logger *zap.SugaredLogger
+}
+
+func NewLogPublisher(logger *zap.SugaredLogger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event Event) error {
+	p.logger.Infow("Event published",
+		"event_id", event.ID,
+		"type", event.Type,
+		"application_id", event.ApplicationID,
+		"subject_id", event.SubjectID,
+		"data", event.Data,
+	)
+	return nil
+}
+
+// Handler reacts to an event published on a Bus
+type Handler func(ctx context.Context, event Event) error
+
+// Bus delivers events to in-process subscribers
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe registers a handler for an event type, or for every event with "*"
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	handlers := append(append([]Handler(nil), b.handlers[event.Type]...), b.handlers["*"]...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			return fmt.Errorf("%s handler failed: %w", event.Type, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/requestmeta"
+)
+
+// AdminMiddleware authenticates operators with a static token. Admin routes
+// manage server-wide state, so application tokens are never accepted.
+type AdminMiddleware struct {
+	token string
+}
+
+func NewAdminMiddleware(token string) *AdminMiddleware {
+	return &AdminMiddleware{
+		token: token,
+	}
+}
+
+func (m *AdminMiddleware) Handler() gin.HandlerFunc {
+	expected := sha256.Sum256([]byte(m.token))
+
+	return func(c *gin.Context) {
+		// Admin routes are disabled without a configured token
+		if m.token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "admin API is disabled",
+			})
+			return
+		}
+
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "authorization header is required",
+			})
+			return
+		}
+
+		// Compare digests so the check takes the same time for any input
+		actual := sha256.Sum256([]byte(token))
+		if subtle.ConstantTimeCompare(actual[:], expected[:]) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "invalid admin token",
+			})
+			return
+		}
+
+		c.Request = c.Request.WithContext(
+			requestmeta.WithPrincipal(c.Request.Context(), "operator"),
+		)
+		c.Next()
+	}
+}
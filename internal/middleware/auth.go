@@ -1,23 +1,38 @@
 package middleware
 
 import (
+	"context"
+	"crypto/ed25519"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/requestmeta"
 )
 
+// KeyResolver looks up the public key used to verify tokens signed with the given key ID
+type KeyResolver interface {
+	PublicKey(ctx context.Context, kid string) (ed25519.PublicKey, error)
+}
+
 type AuthMiddleware struct {
 	jwtSecret string
+	// legacyUntil ends acceptance of tokens signed with the shared secret
+	legacyUntil time.Time
+	keys        KeyResolver
 }
 
-func NewAuthMiddleware(jwtSecret string) *AuthMiddleware {
+func NewAuthMiddleware(jwtSecret string, legacyUntil time.Time, keys KeyResolver) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtSecret: jwtSecret,
+		jwtSecret:   jwtSecret,
+		legacyUntil: legacyUntil,
+		keys:        keys,
 	}
 }
 
@@ -46,10 +61,22 @@ func (m *AuthMiddleware) Handler() gin.HandlerFunc {
 		// Parse and validate JWT token
 		token, err := jwt.Parse(bearerToken[1], func(token *jwt.Token) (interface{}, error) {
 			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			switch token.Method.(type) {
+			case *jwt.SigningMethodEd25519:
+				kid, _ := token.Header["kid"].(string)
+				if kid == "" {
+					return nil, errors.New("missing kid header")
+				}
+				return m.keys.PublicKey(c.Request.Context(), kid)
+			case *jwt.SigningMethodHMAC:
+				// Legacy tokens signed with the shared secret, until the deadline
+				if !time.Now().Before(m.legacyUntil) {
+					return nil, errors.New("legacy HMAC tokens are no longer accepted")
+				}
+				return []byte(m.jwtSecret), nil
+			default:
 				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 			}
-			return []byte(m.jwtSecret), nil
 		})
 
 		if err != nil {
@@ -82,6 +109,9 @@ func (m *AuthMiddleware) Handler() gin.HandlerFunc {
 
 		// Set application ID in context
 		c.Set("application_id", applicationID)
+		c.Request = c.Request.WithContext(
+			requestmeta.WithPrincipal(c.Request.Context(), "application:"+applicationID.String()),
+		)
 		c.Next()
 	}
 }
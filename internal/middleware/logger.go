@@ -55,6 +55,7 @@ func RequestLogger() gin.HandlerFunc {
 
 		// Log request details
 		l.Infow("Request processed",
+			"request_id", c.GetString("request_id"),
 			"method", c.Request.Method,
 			"path", c.Request.URL.Path,
 			"status", c.Writer.Status(),
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/requestmeta"
+)
+
+// RequestIDHeader carries the request ID, either supplied by the caller or
+// generated by the server
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 64
+
+// RequestMetadata returns a middleware that attaches the request ID, client IP
+// and user agent to the request context, where services pick them up when
+// recording license activities
+func RequestMetadata() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Keep the caller's request ID so requests can be traced across services
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Set("request_id", requestID)
+
+		ctx := requestmeta.NewContext(c.Request.Context(), requestmeta.Metadata{
+			RequestID: requestID,
+			IPAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -13,25 +13,61 @@ type Base struct {
 }
 
 type Application struct {
-	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	Name        string    `gorm:"type:varchar(255);not null" json:"name"`
-	Description string    `gorm:"type:text" json:"description"`
-	Version     string    `gorm:"type:varchar(50)" json:"version"`
-	APIKey      string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"api_key"`
-	APISecret   string    `gorm:"type:varchar(128);not null" json:"api_secret"`
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Name            string    `gorm:"type:varchar(255);not null" json:"name"`
+	Description     string    `gorm:"type:text" json:"description"`
+	Version         string    `gorm:"type:varchar(50)" json:"version"`
+	APIKey          string    `gorm:"type:varchar(64);uniqueIndex;not null" json:"api_key"`
+	APISecret       string    `gorm:"type:varchar(128);not null" json:"api_secret"`
+	GracePeriodDays int       `gorm:"not null;default:0" json:"grace_period_days"`
+	KeyFormat       string    `gorm:"type:varchar(20);not null;default:'hex'" json:"key_format"`
+	KeyPrefix       string    `gorm:"type:varchar(16);not null;default:''" json:"key_prefix"`
+	KeyGroups       int       `gorm:"not null;default:4" json:"key_groups"`
+	KeyGroupSize    int       `gorm:"not null;default:5" json:"key_group_size"`
+	KeyAlphabet     string    `gorm:"type:varchar(36);not null;default:''" json:"key_alphabet"`
 	Base
 }
 
+// License key formats
+const (
+	KeyFormatHex     = "hex"
+	KeyFormatGrouped = "grouped"
+)
+
 type LicenseType struct {
-	ID            uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	ApplicationID uuid.UUID      `gorm:"type:uuid;not null" json:"application_id"`
-	Name          string         `gorm:"type:varchar(255);not null" json:"name"`
-	Description   string         `gorm:"type:text" json:"description"`
-	DurationDays  int            `gorm:"not null" json:"duration_days"`
-	Price         float64        `gorm:"type:decimal(10,2);not null" json:"price"`
-	IsActive      bool           `gorm:"default:true" json:"is_active"`
-	Features      map[string]any `gorm:"type:jsonb;default:'{}'" json:"features"`
-	Application   Application    `gorm:"foreignKey:ApplicationID;constraint:OnDelete:CASCADE" json:"-"`
+	ID                uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ApplicationID     uuid.UUID      `gorm:"type:uuid;not null" json:"application_id"`
+	Name              string         `gorm:"type:varchar(255);not null" json:"name"`
+	Description       string         `gorm:"type:text" json:"description"`
+	DurationDays      int            `gorm:"not null" json:"duration_days"`
+	Price             float64        `gorm:"type:decimal(10,2);not null" json:"price"`
+	IsActive          bool           `gorm:"default:true" json:"is_active"`
+	IsTrial           bool           `gorm:"default:false" json:"is_trial"`
+	IsAddon           bool           `gorm:"default:false" json:"is_addon"`
+	Features          map[string]any `gorm:"type:jsonb;default:'{}'" json:"features"`
+	GracePeriodDays   *int           `json:"grace_period_days"`
+	VersionConstraint string         `gorm:"type:varchar(255);not null;default:''" json:"version_constraint"`
+	Application       Application    `gorm:"foreignKey:ApplicationID;constraint:OnDelete:CASCADE" json:"-"`
+	Base
+}
+
+// Feature value types
+const (
+	FeatureTypeBool   = "bool"
+	FeatureTypeInt    = "int"
+	FeatureTypeEnum   = "enum"
+	FeatureTypeString = "string"
+)
+
+type FeatureDefinition struct {
+	ID            uuid.UUID   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ApplicationID uuid.UUID   `gorm:"type:uuid;not null" json:"application_id"`
+	Key           string      `gorm:"type:varchar(100);not null" json:"key"`
+	Type          string      `gorm:"type:varchar(20);not null" json:"type"`
+	Description   string      `gorm:"type:text" json:"description"`
+	DefaultValue  any         `gorm:"type:jsonb;serializer:json" json:"default"`
+	EnumValues    []string    `gorm:"type:jsonb;serializer:json;not null;default:'[]'" json:"enum_values,omitempty"`
+	Application   Application `gorm:"foreignKey:ApplicationID;constraint:OnDelete:CASCADE" json:"-"`
 	Base
 }
 
@@ -50,25 +86,232 @@ type Client struct {
 }
 
 type License struct {
-	ID               uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	ApplicationID    uuid.UUID      `gorm:"type:uuid;not null" json:"application_id"`
-	LicenseTypeID    uuid.UUID      `gorm:"type:uuid;not null" json:"license_type_id"`
-	ClientID         uuid.UUID      `gorm:"type:uuid;not null" json:"client_id"`
-	LicenseKey       string         `gorm:"type:varchar(128);uniqueIndex;not null" json:"license_key"`
-	StartDate        time.Time      `gorm:"type:date;not null" json:"start_date"`
-	ExpiryDate       time.Time      `gorm:"type:date;not null" json:"expiry_date"`
-	UsageLimits      map[string]any `gorm:"type:jsonb;default:'{}'" json:"usage_limits"`
-	CurrentUsage     map[string]any `gorm:"type:jsonb;default:'{}'" json:"current_usage"`
-	IsActive         bool           `gorm:"default:true" json:"is_active"`
-	IsRevoked        bool           `gorm:"default:false" json:"is_revoked"`
-	RevocationReason *string        `gorm:"type:text" json:"revocation_reason"`
-	LastCheck        *time.Time     `gorm:"type:timestamp with time zone" json:"last_check"`
-	Application      Application    `gorm:"foreignKey:ApplicationID;constraint:OnDelete:CASCADE" json:"-"`
-	LicenseType      LicenseType    `gorm:"foreignKey:LicenseTypeID" json:"-"`
-	Client           Client         `gorm:"foreignKey:ClientID" json:"-"`
+	ID                uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ApplicationID     uuid.UUID      `gorm:"type:uuid;not null" json:"application_id"`
+	LicenseTypeID     uuid.UUID      `gorm:"type:uuid;not null" json:"license_type_id"`
+	ClientID          uuid.UUID      `gorm:"type:uuid;not null" json:"client_id"`
+	LicenseKey        string         `gorm:"type:varchar(128);uniqueIndex;not null" json:"license_key"`
+	StartDate         time.Time      `gorm:"type:date;not null" json:"start_date"`
+	ExpiryDate        time.Time      `gorm:"type:date;not null" json:"expiry_date"`
+	UsageLimits       map[string]any `gorm:"type:jsonb;default:'{}'" json:"usage_limits"`
+	CurrentUsage      map[string]any `gorm:"type:jsonb;default:'{}'" json:"current_usage"`
+	UsageResetPeriods map[string]any `gorm:"type:jsonb;default:'{}'" json:"usage_reset_periods"`
+	FeatureOverrides  map[string]any `gorm:"type:jsonb;default:'{}'" json:"feature_overrides"`
+	IsActive          bool           `gorm:"default:true" json:"is_active"`
+	IsRevoked         bool           `gorm:"default:false" json:"is_revoked"`
+	RevocationReason  *string        `gorm:"type:text" json:"revocation_reason"`
+	IsSuspended       bool           `gorm:"default:false" json:"is_suspended"`
+	SuspensionReason  *string        `gorm:"type:text" json:"suspension_reason"`
+	SuspendedAt       *time.Time     `gorm:"type:timestamp with time zone" json:"suspended_at"`
+	ReinstateAt       *time.Time     `gorm:"type:timestamp with time zone" json:"reinstate_at"`
+	LastCheck         *time.Time     `gorm:"type:timestamp with time zone" json:"last_check"`
+	MaxActivations    *int           `json:"max_activations"`
+	MaxLeases         *int           `json:"max_leases"`
+	VersionConstraint string         `gorm:"type:varchar(255);not null;default:''" json:"version_constraint"`
+	Application       Application    `gorm:"foreignKey:ApplicationID;constraint:OnDelete:CASCADE" json:"-"`
+	LicenseType       LicenseType    `gorm:"foreignKey:LicenseTypeID" json:"-"`
+	Client            Client         `gorm:"foreignKey:ClientID" json:"-"`
+	Addons            []LicenseAddon `gorm:"foreignKey:LicenseID" json:"addons"`
+	LicenseFile       string         `gorm:"-" json:"license_file,omitempty"`
+	Base
+}
+
+// LicenseAddon attaches an add-on license type to a base license. The add-on
+// has its own validity period and adds the features of its type.
+type LicenseAddon struct {
+	ID            uuid.UUID   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	LicenseID     uuid.UUID   `gorm:"type:uuid;not null" json:"license_id"`
+	LicenseTypeID uuid.UUID   `gorm:"type:uuid;not null" json:"license_type_id"`
+	StartDate     time.Time   `gorm:"type:date;not null" json:"start_date"`
+	ExpiryDate    time.Time   `gorm:"type:date;not null" json:"expiry_date"`
+	LicenseType   LicenseType `gorm:"foreignKey:LicenseTypeID" json:"license_type"`
+	Base
+}
+
+// Subscription billing intervals
+const (
+	BillingIntervalMonthly = "monthly"
+	BillingIntervalYearly  = "yearly"
+)
+
+// Subscription statuses
+const (
+	SubscriptionActive   = "active"
+	SubscriptionPastDue  = "past_due"
+	SubscriptionCanceled = "canceled"
+)
+
+// Subscription bills a license on a recurring interval. The license expires
+// at the end of the current period unless the subscription is renewed.
+type Subscription struct {
+	ID                 uuid.UUID   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ApplicationID      uuid.UUID   `gorm:"type:uuid;not null" json:"application_id"`
+	LicenseID          uuid.UUID   `gorm:"type:uuid;not null;uniqueIndex" json:"license_id"`
+	BillingInterval    string      `gorm:"type:varchar(20);not null" json:"billing_interval"`
+	Status             string      `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
+	CurrentPeriodStart time.Time   `gorm:"type:timestamp with time zone;not null" json:"current_period_start"`
+	CurrentPeriodEnd   time.Time   `gorm:"type:timestamp with time zone;not null" json:"current_period_end"`
+	CancelAtPeriodEnd  bool        `gorm:"not null;default:false" json:"cancel_at_period_end"`
+	CanceledAt         *time.Time  `gorm:"type:timestamp with time zone" json:"canceled_at"`
+	ExternalID         string      `gorm:"type:varchar(255);not null;default:''" json:"external_id"`
+	Application        Application `gorm:"foreignKey:ApplicationID;constraint:OnDelete:CASCADE" json:"-"`
+	License            License     `gorm:"foreignKey:LicenseID;constraint:OnDelete:CASCADE" json:"-"`
 	Base
 }
 
+type Activation struct {
+	ID                 uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	LicenseID          uuid.UUID `gorm:"type:uuid;not null" json:"license_id"`
+	MachineFingerprint string    `gorm:"type:varchar(255);not null" json:"machine_fingerprint"`
+	Hostname           string    `gorm:"type:varchar(255)" json:"hostname"`
+	Platform           string    `gorm:"type:varchar(100)" json:"platform"`
+	IPAddress          string    `gorm:"type:varchar(45)" json:"ip_address"`
+	FirstSeenAt        time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"first_seen_at"`
+	LastSeenAt         time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"last_seen_at"`
+	License            License   `gorm:"foreignKey:LicenseID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+type LicenseLease struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	LicenseID       uuid.UUID `gorm:"type:uuid;not null" json:"license_id"`
+	Holder          string    `gorm:"type:varchar(255);not null" json:"holder"`
+	IPAddress       string    `gorm:"type:varchar(45)" json:"ip_address"`
+	TTLSeconds      int       `gorm:"column:ttl_seconds;not null" json:"ttl_seconds"`
+	ExpiresAt       time.Time `gorm:"type:timestamp with time zone;not null" json:"expires_at"`
+	LastHeartbeatAt time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"last_heartbeat_at"`
+	CreatedAt       time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	License         License   `gorm:"foreignKey:LicenseID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Usage reset periods
+const (
+	UsageResetDaily   = "daily"
+	UsageResetMonthly = "monthly"
+	UsageResetYearly  = "yearly"
+)
+
+type UsageHistory struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	LicenseID   uuid.UUID `gorm:"type:uuid;not null" json:"license_id"`
+	Metric      string    `gorm:"type:varchar(255);not null" json:"metric"`
+	PeriodStart time.Time `gorm:"type:timestamp with time zone;not null" json:"period_start"`
+	PeriodEnd   time.Time `gorm:"type:timestamp with time zone;not null" json:"period_end"`
+	Used        float64   `gorm:"type:numeric;not null" json:"used"`
+	Limit       *float64  `gorm:"column:usage_limit;type:numeric" json:"limit"`
+	CreatedAt   time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
+	License     License   `gorm:"foreignKey:LicenseID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (UsageHistory) TableName() string {
+	return "usage_history"
+}
+
+// ExpiryNotice records that a license was warned about its expiry within a
+// notification window
+type ExpiryNotice struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	LicenseID  uuid.UUID `gorm:"type:uuid;not null" json:"license_id"`
+	WindowDays int       `gorm:"not null" json:"window_days"`
+	ExpiryDate time.Time `gorm:"type:date;not null" json:"expiry_date"`
+	SentAt     time.Time `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"sent_at"`
+	License    License   `gorm:"foreignKey:LicenseID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// WebhookEndpoint receives the events of an application it subscribed to
+type WebhookEndpoint struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ApplicationID uuid.UUID `gorm:"type:uuid;not null" json:"application_id"`
+	URL           string    `gorm:"type:text;not null" json:"url"`
+	// Secret signs the deliveries; it is only returned when the endpoint is created
+	Secret      string      `gorm:"type:varchar(128);not null" json:"secret,omitempty"`
+	EventTypes  []string    `gorm:"type:jsonb;serializer:json;not null;default:'[]'" json:"event_types"`
+	Description string      `gorm:"type:text" json:"description"`
+	IsActive    bool        `gorm:"default:true" json:"is_active"`
+	Application Application `gorm:"foreignKey:ApplicationID;constraint:OnDelete:CASCADE" json:"-"`
+	Base
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookDelivery is one event queued for one endpoint, together with the
+// outcome of its latest attempt
+type WebhookDelivery struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EndpointID uuid.UUID `gorm:"type:uuid;not null" json:"endpoint_id"`
+	EventID    uuid.UUID `gorm:"type:uuid;not null" json:"event_id"`
+	// RedeliveryOf is the delivery a manual redelivery was made from
+	RedeliveryOf   *uuid.UUID      `gorm:"type:uuid" json:"redelivery_of,omitempty"`
+	EventType      string          `gorm:"type:varchar(100);not null" json:"event_type"`
+	Payload        map[string]any  `gorm:"type:jsonb;serializer:json;not null" json:"payload"`
+	Status         string          `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Attempts       int             `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time       `gorm:"type:timestamp with time zone;not null" json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `gorm:"type:timestamp with time zone" json:"last_attempt_at"`
+	ResponseStatus *int            `json:"response_status"`
+	LastError      string          `gorm:"type:text" json:"last_error,omitempty"`
+	Endpoint       WebhookEndpoint `gorm:"foreignKey:EndpointID;constraint:OnDelete:CASCADE" json:"-"`
+	Base
+}
+
+// OutboxEvent is a domain event stored in the same transaction as the change
+// it describes, waiting to be published
+type OutboxEvent struct {
+	ID            uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	Type          string         `gorm:"type:varchar(100);not null" json:"type"`
+	ApplicationID uuid.UUID      `gorm:"type:uuid;not null" json:"application_id"`
+	SubjectID     uuid.UUID      `gorm:"type:uuid;not null" json:"subject_id"`
+	Data          map[string]any `gorm:"type:jsonb;serializer:json;not null" json:"data"`
+	OccurredAt    time.Time      `gorm:"type:timestamp with time zone;not null" json:"occurred_at"`
+	PublishedAt   *time.Time     `gorm:"type:timestamp with time zone" json:"published_at"`
+	Attempts      int            `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time      `gorm:"type:timestamp with time zone;not null" json:"next_attempt_at"`
+	LastError     string         `gorm:"type:text" json:"last_error,omitempty"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
+
+// Risk reason codes
+const (
+	RiskReasonDistinctIPs          = "distinct_ips"
+	RiskReasonDistinctFingerprints = "distinct_fingerprints"
+	RiskReasonValidationBurst      = "validation_burst"
+	RiskReasonImpossibleTravel     = "impossible_travel"
+)
+
+// RiskReport is the latest key sharing analysis of a license
+type RiskReport struct {
+	LicenseID            uuid.UUID    `gorm:"type:uuid;primary_key" json:"license_id"`
+	Score                int          `gorm:"not null;default:0" json:"score"`
+	Reasons              []RiskReason `gorm:"type:jsonb;serializer:json;not null;default:'[]'" json:"reasons"`
+	DistinctIPs          int          `gorm:"not null;default:0" json:"distinct_ips"`
+	DistinctFingerprints int          `gorm:"not null;default:0" json:"distinct_fingerprints"`
+	WindowStart          time.Time    `gorm:"type:timestamp with time zone;not null" json:"window_start"`
+	WindowEnd            time.Time    `gorm:"type:timestamp with time zone;not null" json:"window_end"`
+	// SuspendedAt is set when the analysis suspended the license
+	SuspendedAt *time.Time `gorm:"type:timestamp with time zone" json:"suspended_at,omitempty"`
+	AnalyzedAt  time.Time  `gorm:"type:timestamp with time zone;not null" json:"analyzed_at"`
+	License     License    `gorm:"foreignKey:LicenseID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (RiskReport) TableName() string {
+	return "license_risk_reports"
+}
+
+// RiskReason explains part of a risk score
+type RiskReason struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Points  int    `json:"points"`
+}
+
 type LicenseActivity struct {
 	ID           uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
 	LicenseID    uuid.UUID      `gorm:"type:uuid;not null" json:"license_id"`
@@ -77,6 +320,8 @@ type LicenseActivity struct {
 	Metadata     map[string]any `gorm:"type:jsonb;default:'{}'" json:"metadata"`
 	IPAddress    string         `gorm:"type:varchar(45)" json:"ip_address"`
 	UserAgent    string         `gorm:"type:text" json:"user_agent"`
+	RequestID    string         `gorm:"type:varchar(64)" json:"request_id"`
+	Principal    string         `gorm:"type:varchar(255)" json:"principal"`
 	License      License        `gorm:"foreignKey:LicenseID;constraint:OnDelete:CASCADE" json:"-"`
 	CreatedAt    time.Time      `gorm:"type:timestamp with time zone;default:CURRENT_TIMESTAMP" json:"created_at"`
 }
@@ -93,6 +338,24 @@ type APIToken struct {
 	Base
 }
 
+// Signing key statuses
+const (
+	SigningKeyStatusActive   = "active"
+	SigningKeyStatusRetiring = "retiring"
+	SigningKeyStatusRetired  = "retired"
+)
+
+type SigningKey struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	KeyID      string     `gorm:"column:kid;type:varchar(64);uniqueIndex;not null" json:"kid"`
+	Algorithm  string     `gorm:"type:varchar(20);not null;default:'EdDSA'" json:"algorithm"`
+	PublicKey  string     `gorm:"type:text;not null" json:"public_key"`
+	PrivateKey string     `gorm:"type:text;not null" json:"-"`
+	Status     string     `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
+	RetiredAt  *time.Time `gorm:"type:timestamp with time zone" json:"retired_at"`
+	Base
+}
+
 // BeforeCreate will set a UUID rather than numeric ID
 func (base *Base) BeforeCreate(tx *gorm.DB) error {
 	base.CreatedAt = time.Now()
@@ -12,6 +12,9 @@ var (
 	// ErrInvalidInput is returned when the input data is invalid
 	ErrInvalidInput = errors.New("invalid input")
 
+	// ErrLimitExceeded is returned when an insert would exceed a configured limit
+	ErrLimitExceeded = errors.New("limit exceeded")
+
 	// ErrForeignKeyViolation is returned when a foreign key constraint is violated
 	ErrForeignKeyViolation = errors.New("foreign key violation")
 )
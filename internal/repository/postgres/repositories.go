@@ -2,11 +2,14 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/LywwKkA-aD/golicensemanager/internal/models"
 	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
@@ -22,7 +25,7 @@ func NewApplicationRepository(db *gorm.DB) repository.ApplicationRepository {
 }
 
 func (r *applicationRepo) Create(ctx context.Context, app *models.Application) (*models.Application, error) {
-	if err := r.db.WithContext(ctx).Create(app).Error; err != nil {
+	if err := conn(ctx, r.db).Create(app).Error; err != nil {
 		return nil, fmt.Errorf("failed to create application: %w", err)
 	}
 	return app, nil
@@ -30,7 +33,7 @@ func (r *applicationRepo) Create(ctx context.Context, app *models.Application) (
 
 func (r *applicationRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Application, error) {
 	var app models.Application
-	if err := r.db.WithContext(ctx).First(&app, "id = ?", id).Error; err != nil {
+	if err := conn(ctx, r.db).First(&app, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, repository.ErrNotFound
 		}
@@ -41,7 +44,7 @@ func (r *applicationRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Ap
 
 func (r *applicationRepo) GetByAPIKey(ctx context.Context, apiKey string) (*models.Application, error) {
 	var app models.Application
-	if err := r.db.WithContext(ctx).First(&app, "api_key = ?", apiKey).Error; err != nil {
+	if err := conn(ctx, r.db).First(&app, "api_key = ?", apiKey).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, repository.ErrNotFound
 		}
@@ -52,21 +55,21 @@ func (r *applicationRepo) GetByAPIKey(ctx context.Context, apiKey string) (*mode
 
 func (r *applicationRepo) List(ctx context.Context) ([]models.Application, error) {
 	var apps []models.Application
-	if err := r.db.WithContext(ctx).Find(&apps).Error; err != nil {
+	if err := conn(ctx, r.db).Find(&apps).Error; err != nil {
 		return nil, fmt.Errorf("failed to list applications: %w", err)
 	}
 	return apps, nil
 }
 
 func (r *applicationRepo) Update(ctx context.Context, app *models.Application) (*models.Application, error) {
-	if err := r.db.WithContext(ctx).Save(app).Error; err != nil {
+	if err := conn(ctx, r.db).Save(app).Error; err != nil {
 		return nil, fmt.Errorf("failed to update application: %w", err)
 	}
 	return app, nil
 }
 
 func (r *applicationRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).Delete(&models.Application{}, "id = ?", id)
+	result := conn(ctx, r.db).Delete(&models.Application{}, "id = ?", id)
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete application: %w", result.Error)
 	}
@@ -86,7 +89,7 @@ func NewLicenseTypeRepository(db *gorm.DB) repository.LicenseTypeRepository {
 }
 
 func (r *licenseTypeRepo) Create(ctx context.Context, licenseType *models.LicenseType) (*models.LicenseType, error) {
-	if err := r.db.WithContext(ctx).Create(licenseType).Error; err != nil {
+	if err := conn(ctx, r.db).Create(licenseType).Error; err != nil {
 		return nil, fmt.Errorf("failed to create license type: %w", err)
 	}
 	return licenseType, nil
@@ -94,7 +97,7 @@ func (r *licenseTypeRepo) Create(ctx context.Context, licenseType *models.Licens
 
 func (r *licenseTypeRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.LicenseType, error) {
 	var licenseType models.LicenseType
-	if err := r.db.WithContext(ctx).First(&licenseType, "id = ?", id).Error; err != nil {
+	if err := conn(ctx, r.db).First(&licenseType, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, repository.ErrNotFound
 		}
@@ -105,21 +108,21 @@ func (r *licenseTypeRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Li
 
 func (r *licenseTypeRepo) List(ctx context.Context, applicationID uuid.UUID) ([]models.LicenseType, error) {
 	var types []models.LicenseType
-	if err := r.db.WithContext(ctx).Where("application_id = ?", applicationID).Find(&types).Error; err != nil {
+	if err := conn(ctx, r.db).Where("application_id = ?", applicationID).Find(&types).Error; err != nil {
 		return nil, fmt.Errorf("failed to list license types: %w", err)
 	}
 	return types, nil
 }
 
 func (r *licenseTypeRepo) Update(ctx context.Context, licenseType *models.LicenseType) (*models.LicenseType, error) {
-	if err := r.db.WithContext(ctx).Save(licenseType).Error; err != nil {
+	if err := conn(ctx, r.db).Save(licenseType).Error; err != nil {
 		return nil, fmt.Errorf("failed to update license type: %w", err)
 	}
 	return licenseType, nil
 }
 
 func (r *licenseTypeRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).Delete(&models.LicenseType{}, "id = ?", id)
+	result := conn(ctx, r.db).Delete(&models.LicenseType{}, "id = ?", id)
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete license type: %w", result.Error)
 	}
@@ -129,6 +132,79 @@ func (r *licenseTypeRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// featureDefinitionRepo implements repository.FeatureDefinitionRepository
+type featureDefinitionRepo struct {
+	db *gorm.DB
+}
+
+func NewFeatureDefinitionRepository(db *gorm.DB) repository.FeatureDefinitionRepository {
+	return &featureDefinitionRepo{db: db}
+}
+
+func (r *featureDefinitionRepo) Create(ctx context.Context, feature *models.FeatureDefinition) (*models.FeatureDefinition, error) {
+	if err := conn(ctx, r.db).Create(feature).Error; err != nil {
+		return nil, fmt.Errorf("failed to create feature definition: %w", err)
+	}
+	return feature, nil
+}
+
+func (r *featureDefinitionRepo) GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.FeatureDefinition, error) {
+	var feature models.FeatureDefinition
+	if err := conn(ctx, r.db).
+		Where("application_id = ? AND id = ?", applicationID, id).
+		First(&feature).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get feature definition: %w", err)
+	}
+	return &feature, nil
+}
+
+func (r *featureDefinitionRepo) GetByKey(ctx context.Context, applicationID uuid.UUID, key string) (*models.FeatureDefinition, error) {
+	var feature models.FeatureDefinition
+	if err := conn(ctx, r.db).
+		Where("application_id = ? AND key = ?", applicationID, key).
+		First(&feature).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get feature definition: %w", err)
+	}
+	return &feature, nil
+}
+
+func (r *featureDefinitionRepo) List(ctx context.Context, applicationID uuid.UUID) ([]models.FeatureDefinition, error) {
+	var features []models.FeatureDefinition
+	if err := conn(ctx, r.db).
+		Where("application_id = ?", applicationID).
+		Order("key").
+		Find(&features).Error; err != nil {
+		return nil, fmt.Errorf("failed to list feature definitions: %w", err)
+	}
+	return features, nil
+}
+
+func (r *featureDefinitionRepo) Update(ctx context.Context, feature *models.FeatureDefinition) (*models.FeatureDefinition, error) {
+	if err := conn(ctx, r.db).Save(feature).Error; err != nil {
+		return nil, fmt.Errorf("failed to update feature definition: %w", err)
+	}
+	return feature, nil
+}
+
+func (r *featureDefinitionRepo) Delete(ctx context.Context, applicationID, id uuid.UUID) error {
+	result := conn(ctx, r.db).
+		Where("application_id = ? AND id = ?", applicationID, id).
+		Delete(&models.FeatureDefinition{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete feature definition: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
 // licenseRepo implements repository.LicenseRepository
 type licenseRepo struct {
 	db *gorm.DB
@@ -139,7 +215,7 @@ func NewLicenseRepository(db *gorm.DB) repository.LicenseRepository {
 }
 
 func (r *licenseRepo) Create(ctx context.Context, license *models.License) (*models.License, error) {
-	if err := r.db.WithContext(ctx).Create(license).Error; err != nil {
+	if err := conn(ctx, r.db).Omit("Addons").Create(license).Error; err != nil {
 		return nil, fmt.Errorf("failed to create license: %w", err)
 	}
 	return license, nil
@@ -147,9 +223,12 @@ func (r *licenseRepo) Create(ctx context.Context, license *models.License) (*mod
 
 func (r *licenseRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.License, error) {
 	var license models.License
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
+		Preload("Application").
 		Preload("LicenseType").
 		Preload("Client").
+		Preload("Addons", orderAddons).
+		Preload("Addons.LicenseType").
 		First(&license, "id = ?", id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, repository.ErrNotFound
@@ -161,9 +240,12 @@ func (r *licenseRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.Licens
 
 func (r *licenseRepo) GetByKey(ctx context.Context, licenseKey string) (*models.License, error) {
 	var license models.License
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
+		Preload("Application").
 		Preload("LicenseType").
 		Preload("Client").
+		Preload("Addons", orderAddons).
+		Preload("Addons.LicenseType").
 		First(&license, "license_key = ?", licenseKey).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, repository.ErrNotFound
@@ -175,9 +257,11 @@ func (r *licenseRepo) GetByKey(ctx context.Context, licenseKey string) (*models.
 
 func (r *licenseRepo) List(ctx context.Context, filters repository.LicenseFilters) ([]models.License, error) {
 	var licenses []models.License
-	query := r.db.WithContext(ctx).
+	query := conn(ctx, r.db).
 		Preload("LicenseType").
 		Preload("Client").
+		Preload("Addons", orderAddons).
+		Preload("Addons.LicenseType").
 		Where("application_id = ?", filters.ApplicationID)
 
 	if filters.ClientID != nil {
@@ -197,14 +281,16 @@ func (r *licenseRepo) List(ctx context.Context, filters repository.LicenseFilter
 }
 
 func (r *licenseRepo) Update(ctx context.Context, license *models.License) (*models.License, error) {
-	if err := r.db.WithContext(ctx).Save(license).Error; err != nil {
+	// Usage counters are only changed atomically, see IncrementUsage and SetUsage.
+	// Add-ons are managed by the LicenseAddonRepository.
+	if err := conn(ctx, r.db).Omit("current_usage", "Addons").Save(license).Error; err != nil {
 		return nil, fmt.Errorf("failed to update license: %w", err)
 	}
 	return license, nil
 }
 
 func (r *licenseRepo) Delete(ctx context.Context, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).Delete(&models.License{}, "id = ?", id)
+	result := conn(ctx, r.db).Delete(&models.License{}, "id = ?", id)
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete license: %w", result.Error)
 	}
@@ -215,18 +301,24 @@ func (r *licenseRepo) Delete(ctx context.Context, id uuid.UUID) error {
 }
 
 func (r *licenseRepo) CreateActivity(ctx context.Context, activity *models.LicenseActivity) error {
-	if err := r.db.WithContext(ctx).Create(activity).Error; err != nil {
+	if err := conn(ctx, r.db).Create(activity).Error; err != nil {
 		return fmt.Errorf("failed to create license activity: %w", err)
 	}
 	return nil
 }
 
-func (r *licenseRepo) GetActivities(ctx context.Context, licenseID uuid.UUID) ([]models.LicenseActivity, error) {
+func (r *licenseRepo) GetActivities(ctx context.Context, licenseID uuid.UUID, filters repository.ActivityFilters) ([]models.LicenseActivity, error) {
 	var activities []models.LicenseActivity
-	if err := r.db.WithContext(ctx).
-		Where("license_id = ?", licenseID).
-		Order("created_at DESC").
-		Find(&activities).Error; err != nil {
+	query := conn(ctx, r.db).Where("license_id = ?", licenseID)
+
+	if filters.ActivityType != "" {
+		query = query.Where("activity_type = ?", filters.ActivityType)
+	}
+	if filters.IPAddress != "" {
+		query = query.Where("ip_address = ?", filters.IPAddress)
+	}
+
+	if err := query.Order("created_at DESC").Find(&activities).Error; err != nil {
 		return nil, fmt.Errorf("failed to get license activities: %w", err)
 	}
 	return activities, nil
@@ -234,7 +326,7 @@ func (r *licenseRepo) GetActivities(ctx context.Context, licenseID uuid.UUID) ([
 
 func (r *licenseRepo) HasActiveClientLicenses(ctx context.Context, applicationID, clientID uuid.UUID) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.License{}).
+	err := conn(ctx, r.db).Model(&models.License{}).
 		Where("application_id = ? AND client_id = ? AND is_active = ? AND is_revoked = ?",
 			applicationID, clientID, true, false).
 		Count(&count).Error
@@ -244,6 +336,648 @@ func (r *licenseRepo) HasActiveClientLicenses(ctx context.Context, applicationID
 	return count > 0, nil
 }
 
+func (r *licenseRepo) GetForUpdate(ctx context.Context, id uuid.UUID) (*models.License, error) {
+	var license models.License
+	if err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&license, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to lock license: %w", err)
+	}
+	return &license, nil
+}
+
+func (r *licenseRepo) UpdateTerm(ctx context.Context, id uuid.UUID, expiryDate time.Time, isActive bool) error {
+	result := conn(ctx, r.db).Model(&models.License{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"expiry_date": expiryDate,
+			"is_active":   isActive,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update license term: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *licenseRepo) UpdatePlan(ctx context.Context, license *models.License) error {
+	result := conn(ctx, r.db).Model(license).
+		Select("license_type_id", "usage_limits", "start_date", "expiry_date", "is_active").
+		Updates(license)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update license plan: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *licenseRepo) Reinstate(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := conn(ctx, r.db).Model(&models.License{}).
+		Where("id = ? AND is_suspended = ?", id, true).
+		Updates(map[string]interface{}{
+			"is_suspended":      false,
+			"suspension_reason": nil,
+			"suspended_at":      nil,
+			"reinstate_at":      nil,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to reinstate license: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *licenseRepo) ListDueReinstatements(ctx context.Context, before time.Time) ([]models.License, error) {
+	var licenses []models.License
+	if err := conn(ctx, r.db).
+		Where("is_suspended = ? AND reinstate_at IS NOT NULL AND reinstate_at <= ?", true, before).
+		Find(&licenses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list licenses due for reinstatement: %w", err)
+	}
+	return licenses, nil
+}
+
+func (r *licenseRepo) Transfer(ctx context.Context, ids []uuid.UUID, toClientID uuid.UUID, resetActivations bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.License{}).
+			Where("id IN ?", ids).
+			Update("client_id", toClientID).Error; err != nil {
+			return fmt.Errorf("failed to transfer licenses: %w", err)
+		}
+
+		if resetActivations {
+			if err := tx.Where("license_id IN ?", ids).
+				Delete(&models.Activation{}).Error; err != nil {
+				return fmt.Errorf("failed to reset activations: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *licenseRepo) Expire(ctx context.Context, now time.Time, principal string) (int64, error) {
+	// A single statement, so concurrent runs never deactivate a license twice:
+	// the second UPDATE re-checks is_active after waiting for the row lock.
+	// Grace periods match validation: trials get none, license types may
+	// override the application default.
+	result := conn(ctx, r.db).Exec(`
+		WITH expired AS (
+			UPDATE licenses l
+			SET is_active = false,
+				updated_at = CURRENT_TIMESTAMP
+			FROM license_types lt, applications a
+			WHERE lt.id = l.license_type_id
+				AND a.id = l.application_id
+				AND l.is_active
+				AND NOT l.is_revoked
+				AND (l.expiry_date + CASE WHEN lt.is_trial THEN 0
+					ELSE COALESCE(lt.grace_period_days, a.grace_period_days) END
+				)::timestamp AT TIME ZONE 'UTC' <= @now
+			RETURNING l.id, l.expiry_date
+		)
+		INSERT INTO license_activities (license_id, activity_type, description, metadata, principal, created_at)
+		SELECT id, 'expiration', 'License expired and was deactivated',
+			jsonb_build_object('expiry_date', to_char(expiry_date, 'YYYY-MM-DD')), @principal, @now
+		FROM expired`, map[string]interface{}{
+		"now":       now,
+		"principal": principal,
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to expire licenses: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *licenseRepo) IncrementUsage(ctx context.Context, id uuid.UUID, metric string, delta float64, limit *float64) (float64, error) {
+	args := map[string]interface{}{
+		"id":     id,
+		"metric": metric,
+		"delta":  delta,
+	}
+
+	query := `
+		UPDATE licenses
+		SET current_usage = jsonb_set(
+				COALESCE(current_usage, '{}'::jsonb),
+				ARRAY[CAST(@metric AS text)],
+				to_jsonb(GREATEST(COALESCE((current_usage->>CAST(@metric AS text))::numeric, 0) + CAST(@delta AS numeric), 0))
+			),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = @id`
+	if limit != nil {
+		query += ` AND COALESCE((current_usage->>CAST(@metric AS text))::numeric, 0) + CAST(@delta AS numeric) <= CAST(@limit AS numeric)`
+		args["limit"] = *limit
+	}
+	query += ` RETURNING (current_usage->>CAST(@metric AS text))::float8 AS value`
+
+	var updated []float64
+	if err := conn(ctx, r.db).Raw(query, args).Scan(&updated).Error; err != nil {
+		return 0, fmt.Errorf("failed to increment license usage: %w", err)
+	}
+	if len(updated) > 0 {
+		return updated[0], nil
+	}
+
+	// Nothing was updated: either the license is gone or the limit was hit
+	var current []float64
+	if err := conn(ctx, r.db).Raw(`
+		SELECT COALESCE((current_usage->>CAST(@metric AS text))::float8, 0) AS value
+		FROM licenses
+		WHERE id = @id`, args).Scan(&current).Error; err != nil {
+		return 0, fmt.Errorf("failed to get license usage: %w", err)
+	}
+	if len(current) == 0 {
+		return 0, repository.ErrNotFound
+	}
+	return current[0], repository.ErrLimitExceeded
+}
+
+func (r *licenseRepo) SetUsage(ctx context.Context, id uuid.UUID, usage map[string]float64) error {
+	values, err := json.Marshal(usage)
+	if err != nil {
+		return fmt.Errorf("failed to encode license usage: %w", err)
+	}
+
+	result := conn(ctx, r.db).Exec(`
+		UPDATE licenses
+		SET current_usage = COALESCE(current_usage, '{}'::jsonb) || CAST(? AS jsonb),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`, string(values), id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set license usage: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *licenseRepo) UpdateLastCheck(ctx context.Context, id uuid.UUID, at time.Time) error {
+	if err := conn(ctx, r.db).
+		Model(&models.License{}).
+		Where("id = ?", id).
+		UpdateColumn("last_check", at).Error; err != nil {
+		return fmt.Errorf("failed to update license last check: %w", err)
+	}
+	return nil
+}
+
+// errBatchAborted rolls back an atomic batch after a group failed
+var errBatchAborted = errors.New("batch aborted")
+
+func (r *licenseRepo) CreateBatch(ctx context.Context, groups [][]*models.License, atomic bool) ([]error, error) {
+	groupErrs := make([]error, len(groups))
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		failed := false
+		for i, group := range groups {
+			if len(group) == 0 {
+				continue
+			}
+
+			// Each group gets a savepoint so a failed insert does not abort the transaction
+			savepoint := fmt.Sprintf("batch_group_%d", i)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return fmt.Errorf("failed to create savepoint: %w", err)
+			}
+
+			if err := tx.Create(group).Error; err != nil {
+				if err := tx.RollbackTo(savepoint).Error; err != nil {
+					return fmt.Errorf("failed to roll back to savepoint: %w", err)
+				}
+				groupErrs[i] = fmt.Errorf("failed to create licenses: %w", err)
+				failed = true
+			}
+		}
+
+		if atomic && failed {
+			return errBatchAborted
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchAborted) {
+		return nil, err
+	}
+
+	return groupErrs, nil
+}
+
+// orderAddons preloads add-ons in the order they were attached
+func orderAddons(db *gorm.DB) *gorm.DB {
+	return db.Order("license_addons.created_at")
+}
+
+// licenseAddonRepo implements repository.LicenseAddonRepository
+type licenseAddonRepo struct {
+	db *gorm.DB
+}
+
+func NewLicenseAddonRepository(db *gorm.DB) repository.LicenseAddonRepository {
+	return &licenseAddonRepo{db: db}
+}
+
+func (r *licenseAddonRepo) Create(ctx context.Context, addon *models.LicenseAddon) (*models.LicenseAddon, error) {
+	if err := conn(ctx, r.db).Omit("LicenseType").Create(addon).Error; err != nil {
+		if isUniqueViolation(err) {
+			return nil, repository.ErrDuplicateKey
+		}
+		return nil, fmt.Errorf("failed to create license add-on: %w", err)
+	}
+	return addon, nil
+}
+
+func (r *licenseAddonRepo) GetByID(ctx context.Context, licenseID, id uuid.UUID) (*models.LicenseAddon, error) {
+	var addon models.LicenseAddon
+	if err := conn(ctx, r.db).
+		Preload("LicenseType").
+		First(&addon, "id = ? AND license_id = ?", id, licenseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get license add-on: %w", err)
+	}
+	return &addon, nil
+}
+
+func (r *licenseAddonRepo) GetByType(ctx context.Context, licenseID, licenseTypeID uuid.UUID) (*models.LicenseAddon, error) {
+	var addon models.LicenseAddon
+	if err := conn(ctx, r.db).
+		Preload("LicenseType").
+		First(&addon, "license_id = ? AND license_type_id = ?", licenseID, licenseTypeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get license add-on: %w", err)
+	}
+	return &addon, nil
+}
+
+func (r *licenseAddonRepo) Update(ctx context.Context, addon *models.LicenseAddon) (*models.LicenseAddon, error) {
+	if err := conn(ctx, r.db).Omit("LicenseType").Save(addon).Error; err != nil {
+		return nil, fmt.Errorf("failed to update license add-on: %w", err)
+	}
+	return addon, nil
+}
+
+func (r *licenseAddonRepo) Delete(ctx context.Context, licenseID, id uuid.UUID) error {
+	result := conn(ctx, r.db).Delete(&models.LicenseAddon{}, "id = ? AND license_id = ?", id, licenseID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete license add-on: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// subscriptionRepo implements repository.SubscriptionRepository
+type subscriptionRepo struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionRepository(db *gorm.DB) repository.SubscriptionRepository {
+	return &subscriptionRepo{db: db}
+}
+
+func (r *subscriptionRepo) Create(ctx context.Context, subscription *models.Subscription) (*models.Subscription, error) {
+	if err := conn(ctx, r.db).Create(subscription).Error; err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (r *subscriptionRepo) GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.Subscription, error) {
+	var subscription models.Subscription
+	if err := conn(ctx, r.db).
+		First(&subscription, "id = ? AND application_id = ?", id, applicationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return &subscription, nil
+}
+
+func (r *subscriptionRepo) GetForUpdate(ctx context.Context, id uuid.UUID) (*models.Subscription, error) {
+	var subscription models.Subscription
+	if err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&subscription, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to lock subscription: %w", err)
+	}
+	return &subscription, nil
+}
+
+func (r *subscriptionRepo) GetByLicense(ctx context.Context, licenseID uuid.UUID) (*models.Subscription, error) {
+	var subscription models.Subscription
+	if err := conn(ctx, r.db).
+		First(&subscription, "license_id = ?", licenseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get subscription by license: %w", err)
+	}
+	return &subscription, nil
+}
+
+func (r *subscriptionRepo) List(ctx context.Context, applicationID uuid.UUID, status string) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	query := conn(ctx, r.db).Where("application_id = ?", applicationID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("created_at DESC").Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepo) Update(ctx context.Context, subscription *models.Subscription) (*models.Subscription, error) {
+	if err := conn(ctx, r.db).Omit("Application", "License").Save(subscription).Error; err != nil {
+		return nil, fmt.Errorf("failed to update subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (r *subscriptionRepo) ListPeriodEnded(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := conn(ctx, r.db).Model(&models.Subscription{}).
+		Where("status = ? AND current_period_end <= ?", models.SubscriptionActive, now).
+		Order("current_period_end").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to list ended subscription periods: %w", err)
+	}
+	return ids, nil
+}
+
+// activationRepo implements repository.ActivationRepository
+type activationRepo struct {
+	db *gorm.DB
+}
+
+func NewActivationRepository(db *gorm.DB) repository.ActivationRepository {
+	return &activationRepo{db: db}
+}
+
+func (r *activationRepo) Activate(ctx context.Context, activation *models.Activation, maxActivations int) (*models.Activation, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Lock the license row so concurrent activations are counted one at a time
+		var license models.License
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&license, "id = ?", activation.LicenseID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return repository.ErrNotFound
+			}
+			return fmt.Errorf("failed to lock license: %w", err)
+		}
+
+		var existing models.Activation
+		err := tx.Where("license_id = ? AND machine_fingerprint = ?", activation.LicenseID, activation.MachineFingerprint).
+			First(&existing).Error
+		if err == nil {
+			existing.Hostname = activation.Hostname
+			existing.Platform = activation.Platform
+			existing.IPAddress = activation.IPAddress
+			existing.LastSeenAt = time.Now()
+			if err := tx.Save(&existing).Error; err != nil {
+				return fmt.Errorf("failed to update activation: %w", err)
+			}
+			*activation = existing
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get activation: %w", err)
+		}
+
+		if maxActivations > 0 {
+			var count int64
+			if err := tx.Model(&models.Activation{}).
+				Where("license_id = ?", activation.LicenseID).
+				Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to count activations: %w", err)
+			}
+			if count >= int64(maxActivations) {
+				return repository.ErrLimitExceeded
+			}
+		}
+
+		now := time.Now()
+		activation.FirstSeenAt = now
+		activation.LastSeenAt = now
+		if err := tx.Create(activation).Error; err != nil {
+			return fmt.Errorf("failed to create activation: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return activation, nil
+}
+
+func (r *activationRepo) Deactivate(ctx context.Context, licenseID uuid.UUID, fingerprint string) error {
+	result := conn(ctx, r.db).
+		Where("license_id = ? AND machine_fingerprint = ?", licenseID, fingerprint).
+		Delete(&models.Activation{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete activation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *activationRepo) GetByFingerprint(ctx context.Context, licenseID uuid.UUID, fingerprint string) (*models.Activation, error) {
+	var activation models.Activation
+	if err := conn(ctx, r.db).
+		Where("license_id = ? AND machine_fingerprint = ?", licenseID, fingerprint).
+		First(&activation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get activation: %w", err)
+	}
+	return &activation, nil
+}
+
+func (r *activationRepo) List(ctx context.Context, licenseID uuid.UUID) ([]models.Activation, error) {
+	var activations []models.Activation
+	if err := conn(ctx, r.db).
+		Where("license_id = ?", licenseID).
+		Order("first_seen_at").
+		Find(&activations).Error; err != nil {
+		return nil, fmt.Errorf("failed to list activations: %w", err)
+	}
+	return activations, nil
+}
+
+func (r *activationRepo) Touch(ctx context.Context, id uuid.UUID, ipAddress string) error {
+	updates := map[string]interface{}{"last_seen_at": time.Now()}
+	if ipAddress != "" {
+		updates["ip_address"] = ipAddress
+	}
+
+	if err := conn(ctx, r.db).Model(&models.Activation{}).
+		Where("id = ?", id).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update activation: %w", err)
+	}
+	return nil
+}
+
+// leaseRepo implements repository.LeaseRepository
+type leaseRepo struct {
+	db *gorm.DB
+}
+
+func NewLeaseRepository(db *gorm.DB) repository.LeaseRepository {
+	return &leaseRepo{db: db}
+}
+
+func (r *leaseRepo) Checkout(ctx context.Context, lease *models.LicenseLease, maxLeases int) (*models.LicenseLease, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Lock the license row so concurrent checkouts cannot both take the last seat
+		var license models.License
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&license, "id = ?", lease.LicenseID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return repository.ErrNotFound
+			}
+			return fmt.Errorf("failed to lock license: %w", err)
+		}
+
+		now := time.Now()
+		if err := tx.Where("license_id = ? AND expires_at <= ?", lease.LicenseID, now).
+			Delete(&models.LicenseLease{}).Error; err != nil {
+			return fmt.Errorf("failed to delete expired leases: %w", err)
+		}
+
+		expiresAt := now.Add(time.Duration(lease.TTLSeconds) * time.Second)
+
+		// A holder checking out again keeps its seat
+		var existing models.LicenseLease
+		err := tx.Where("license_id = ? AND holder = ?", lease.LicenseID, lease.Holder).
+			First(&existing).Error
+		if err == nil {
+			existing.IPAddress = lease.IPAddress
+			existing.TTLSeconds = lease.TTLSeconds
+			existing.ExpiresAt = expiresAt
+			existing.LastHeartbeatAt = now
+			if err := tx.Save(&existing).Error; err != nil {
+				return fmt.Errorf("failed to refresh lease: %w", err)
+			}
+			*lease = existing
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to get lease: %w", err)
+		}
+
+		var count int64
+		if err := tx.Model(&models.LicenseLease{}).
+			Where("license_id = ?", lease.LicenseID).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count leases: %w", err)
+		}
+		if count >= int64(maxLeases) {
+			return repository.ErrLimitExceeded
+		}
+
+		lease.ExpiresAt = expiresAt
+		lease.LastHeartbeatAt = now
+		lease.CreatedAt = now
+		if err := tx.Create(lease).Error; err != nil {
+			return fmt.Errorf("failed to create lease: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+func (r *leaseRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.LicenseLease, error) {
+	var lease models.LicenseLease
+	if err := conn(ctx, r.db).First(&lease, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get lease: %w", err)
+	}
+	return &lease, nil
+}
+
+// Renew extends an unexpired lease by its TTL. Expired leases cannot be renewed
+// because their seat may already have been given to someone else.
+func (r *leaseRepo) Renew(ctx context.Context, id uuid.UUID) (*models.LicenseLease, error) {
+	var lease models.LicenseLease
+	now := time.Now()
+	result := conn(ctx, r.db).Model(&lease).
+		Clauses(clause.Returning{}).
+		Where("id = ? AND expires_at > ?", id, now).
+		Updates(map[string]interface{}{
+			"expires_at":        gorm.Expr("CAST(? AS timestamptz) + ttl_seconds * INTERVAL '1 second'", now),
+			"last_heartbeat_at": now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to renew lease: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return &lease, nil
+}
+
+func (r *leaseRepo) Release(ctx context.Context, id uuid.UUID) error {
+	result := conn(ctx, r.db).Delete(&models.LicenseLease{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to release lease: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *leaseRepo) ListActive(ctx context.Context, licenseID uuid.UUID) ([]models.LicenseLease, error) {
+	var leases []models.LicenseLease
+	if err := conn(ctx, r.db).
+		Where("license_id = ? AND expires_at > ?", licenseID, time.Now()).
+		Order("created_at").
+		Find(&leases).Error; err != nil {
+		return nil, fmt.Errorf("failed to list leases: %w", err)
+	}
+	return leases, nil
+}
+
+func (r *leaseRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("expires_at <= ?", time.Now()).
+		Delete(&models.LicenseLease{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired leases: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
 // clientRepo implements repository.ClientRepository
 type clientRepo struct {
 	db *gorm.DB
@@ -254,7 +988,7 @@ func NewClientRepository(db *gorm.DB) repository.ClientRepository {
 }
 
 func (r *clientRepo) Create(ctx context.Context, client *models.Client) (*models.Client, error) {
-	if err := r.db.WithContext(ctx).Create(client).Error; err != nil {
+	if err := conn(ctx, r.db).Create(client).Error; err != nil {
 		return nil, fmt.Errorf("failed to create client: %w", err)
 	}
 	return client, nil
@@ -262,7 +996,7 @@ func (r *clientRepo) Create(ctx context.Context, client *models.Client) (*models
 
 func (r *clientRepo) GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.Client, error) {
 	var client models.Client
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Where("application_id = ? AND id = ?", applicationID, id).
 		First(&client).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -275,7 +1009,7 @@ func (r *clientRepo) GetByID(ctx context.Context, applicationID, id uuid.UUID) (
 
 func (r *clientRepo) List(ctx context.Context, filters repository.ClientFilters) ([]models.Client, error) {
 	var clients []models.Client
-	query := r.db.WithContext(ctx).Where("application_id = ?", filters.ApplicationID)
+	query := conn(ctx, r.db).Where("application_id = ?", filters.ApplicationID)
 
 	if filters.IsActive != nil {
 		query = query.Where("is_active = ?", *filters.IsActive)
@@ -295,14 +1029,14 @@ func (r *clientRepo) List(ctx context.Context, filters repository.ClientFilters)
 }
 
 func (r *clientRepo) Update(ctx context.Context, client *models.Client) (*models.Client, error) {
-	if err := r.db.WithContext(ctx).Save(client).Error; err != nil {
+	if err := conn(ctx, r.db).Save(client).Error; err != nil {
 		return nil, fmt.Errorf("failed to update client: %w", err)
 	}
 	return client, nil
 }
 
 func (r *clientRepo) Delete(ctx context.Context, applicationID, id uuid.UUID) error {
-	result := r.db.WithContext(ctx).
+	result := conn(ctx, r.db).
 		Where("application_id = ? AND id = ?", applicationID, id).
 		Delete(&models.Client{})
 
@@ -317,7 +1051,7 @@ func (r *clientRepo) Delete(ctx context.Context, applicationID, id uuid.UUID) er
 
 func (r *clientRepo) ExistsByEmail(ctx context.Context, applicationID uuid.UUID, email string) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.Client{}).
+	err := conn(ctx, r.db).Model(&models.Client{}).
 		Where("application_id = ? AND email = ?", applicationID, email).
 		Count(&count).Error
 	if err != nil {
@@ -325,3 +1059,543 @@ func (r *clientRepo) ExistsByEmail(ctx context.Context, applicationID uuid.UUID,
 	}
 	return count > 0, nil
 }
+
+// signingKeyRepo implements repository.SigningKeyRepository
+type signingKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewSigningKeyRepository(db *gorm.DB) repository.SigningKeyRepository {
+	return &signingKeyRepo{db: db}
+}
+
+func (r *signingKeyRepo) Create(ctx context.Context, key *models.SigningKey) (*models.SigningKey, error) {
+	if err := conn(ctx, r.db).Create(key).Error; err != nil {
+		return nil, fmt.Errorf("failed to create signing key: %w", err)
+	}
+	return key, nil
+}
+
+func (r *signingKeyRepo) GetByKeyID(ctx context.Context, kid string) (*models.SigningKey, error) {
+	var key models.SigningKey
+	if err := conn(ctx, r.db).First(&key, "kid = ?", kid).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get signing key: %w", err)
+	}
+	return &key, nil
+}
+
+func (r *signingKeyRepo) GetActive(ctx context.Context) (*models.SigningKey, error) {
+	var key models.SigningKey
+	if err := conn(ctx, r.db).
+		Where("status = ?", models.SigningKeyStatusActive).
+		First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get active signing key: %w", err)
+	}
+	return &key, nil
+}
+
+func (r *signingKeyRepo) List(ctx context.Context, statuses ...string) ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	query := conn(ctx, r.db).Order("created_at DESC")
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+
+	if err := query.Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list signing keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Rotate demotes the current active key to retiring and stores the given key
+// as the new active key in a single transaction
+func (r *signingKeyRepo) Rotate(ctx context.Context, key *models.SigningKey) (*models.SigningKey, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.SigningKey{}).
+			Where("status = ?", models.SigningKeyStatusActive).
+			Update("status", models.SigningKeyStatusRetiring).Error; err != nil {
+			return fmt.Errorf("failed to demote active signing key: %w", err)
+		}
+
+		key.Status = models.SigningKeyStatusActive
+		if err := tx.Create(key).Error; err != nil {
+			return fmt.Errorf("failed to create signing key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *signingKeyRepo) UpdateStatus(ctx context.Context, kid, status string) error {
+	updates := map[string]interface{}{"status": status}
+	if status == models.SigningKeyStatusRetired {
+		updates["retired_at"] = gorm.Expr("CURRENT_TIMESTAMP")
+	}
+
+	result := conn(ctx, r.db).Model(&models.SigningKey{}).
+		Where("kid = ?", kid).
+		Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update signing key status: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *signingKeyRepo) UpdatePrivateKey(ctx context.Context, kid, privateKey string) error {
+	result := conn(ctx, r.db).Model(&models.SigningKey{}).
+		Where("kid = ?", kid).
+		Update("private_key", privateKey)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update signing key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// usageRepo implements repository.UsageRepository
+type usageRepo struct {
+	db *gorm.DB
+}
+
+func NewUsageRepository(db *gorm.DB) repository.UsageRepository {
+	return &usageRepo{db: db}
+}
+
+func (r *usageRepo) ListResettable(ctx context.Context) ([]models.License, error) {
+	var licenses []models.License
+	if err := conn(ctx, r.db).
+		Where("usage_reset_periods <> '{}'::jsonb AND is_revoked = ?", false).
+		Find(&licenses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list licenses with usage resets: %w", err)
+	}
+	return licenses, nil
+}
+
+func (r *usageRepo) Reset(ctx context.Context, snapshot *models.UsageHistory) (bool, error) {
+	reset := false
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// Lock the license so no increment slips in between snapshot and reset
+		var counters struct {
+			Used  float64
+			Limit *float64
+		}
+		result := tx.Raw(`
+			SELECT COALESCE((current_usage->>CAST(? AS text))::float8, 0) AS used,
+				(usage_limits->>CAST(? AS text))::float8 AS "limit"
+			FROM licenses
+			WHERE id = ?
+			FOR UPDATE`, snapshot.Metric, snapshot.Metric, snapshot.LicenseID).Scan(&counters)
+		if result.Error != nil {
+			return fmt.Errorf("failed to lock license usage: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return repository.ErrNotFound
+		}
+
+		snapshot.Used = counters.Used
+		snapshot.Limit = counters.Limit
+
+		// The unique period key makes resets idempotent
+		result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(snapshot)
+		if result.Error != nil {
+			return fmt.Errorf("failed to create usage history: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := tx.Exec(`
+			UPDATE licenses
+			SET current_usage = jsonb_set(COALESCE(current_usage, '{}'::jsonb), ARRAY[CAST(? AS text)], '0'::jsonb),
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?`, snapshot.Metric, snapshot.LicenseID).Error; err != nil {
+			return fmt.Errorf("failed to reset license usage: %w", err)
+		}
+
+		reset = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return reset, nil
+}
+
+func (r *usageRepo) ListHistory(ctx context.Context, licenseID uuid.UUID, metric string) ([]models.UsageHistory, error) {
+	var history []models.UsageHistory
+	query := conn(ctx, r.db).Where("license_id = ?", licenseID)
+	if metric != "" {
+		query = query.Where("metric = ?", metric)
+	}
+	if err := query.Order("period_start DESC, metric").Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to list usage history: %w", err)
+	}
+	return history, nil
+}
+
+type expiryNoticeRepo struct {
+	db *gorm.DB
+}
+
+func NewExpiryNoticeRepository(db *gorm.DB) repository.ExpiryNoticeRepository {
+	return &expiryNoticeRepo{db: db}
+}
+
+func (r *expiryNoticeRepo) ListExpiring(ctx context.Context, from, until time.Time) ([]models.License, error) {
+	var licenses []models.License
+	if err := conn(ctx, r.db).
+		Where("is_active = ? AND is_revoked = ? AND expiry_date > ? AND expiry_date <= ?", true, false, from, until).
+		Order("expiry_date").
+		Find(&licenses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list expiring licenses: %w", err)
+	}
+	return licenses, nil
+}
+
+func (r *expiryNoticeRepo) Claim(ctx context.Context, notice *models.ExpiryNotice) (bool, error) {
+	// The unique notice key makes claims idempotent across restarts
+	result := conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(notice)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim expiry notice: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// maxListedDeliveries bounds the delivery log returned per endpoint
+const maxListedDeliveries = 500
+
+type webhookRepo struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) repository.WebhookRepository {
+	return &webhookRepo{db: db}
+}
+
+func (r *webhookRepo) CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	if err := conn(ctx, r.db).Create(endpoint).Error; err != nil {
+		return nil, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return endpoint, nil
+}
+
+func (r *webhookRepo) GetEndpoint(ctx context.Context, applicationID, id uuid.UUID) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := conn(ctx, r.db).
+		First(&endpoint, "id = ? AND application_id = ?", id, applicationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return &endpoint, nil
+}
+
+func (r *webhookRepo) ListEndpoints(ctx context.Context, applicationID uuid.UUID) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if err := conn(ctx, r.db).
+		Where("application_id = ?", applicationID).
+		Order("created_at").
+		Find(&endpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+func (r *webhookRepo) ListSubscribed(ctx context.Context, applicationID uuid.UUID, eventType string) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if err := conn(ctx, r.db).
+		Where("application_id = ? AND is_active = ?", applicationID, true).
+		Where("(event_types @> jsonb_build_array(CAST(? AS text)) OR event_types @> '[\"*\"]'::jsonb)", eventType).
+		Find(&endpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to list subscribed webhook endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+func (r *webhookRepo) UpdateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	if err := conn(ctx, r.db).Save(endpoint).Error; err != nil {
+		return nil, fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+	return endpoint, nil
+}
+
+func (r *webhookRepo) DeleteEndpoint(ctx context.Context, applicationID, id uuid.UUID) error {
+	result := conn(ctx, r.db).
+		Delete(&models.WebhookEndpoint{}, "id = ? AND application_id = ?", id, applicationID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepo) CreateDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	// Events published again after a dispatcher crash are delivered once
+	if err := conn(ctx, r.db).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "endpoint_id"}, {Name: "event_id"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "redelivery_of IS NULL"}}},
+			DoNothing:   true,
+		}).
+		Create(deliveries).Error; err != nil {
+		return fmt.Errorf("failed to create webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepo) ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var ids []uuid.UUID
+	if err := conn(ctx, r.db).Raw(`
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1,
+			last_attempt_at = @now,
+			next_attempt_at = @lease_until,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id IN (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhook_endpoints e ON e.id = d.endpoint_id
+			WHERE d.status IN ('pending', 'failed')
+				AND d.next_attempt_at <= @now
+				AND e.is_active
+			ORDER BY d.next_attempt_at
+			LIMIT @limit
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING id`, map[string]interface{}{
+		"now":         now,
+		"lease_until": leaseUntil,
+		"limit":       limit,
+	}).Scan(&ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := conn(ctx, r.db).
+		Preload("Endpoint").
+		Where("id IN ?", ids).
+		Order("next_attempt_at").
+		Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load claimed webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepo) UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if err := conn(ctx, r.db).Omit("Endpoint").Save(delivery).Error; err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepo) GetDelivery(ctx context.Context, endpointID, id uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := conn(ctx, r.db).
+		First(&delivery, "id = ? AND endpoint_id = ?", id, endpointID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepo) ListDeliveries(ctx context.Context, endpointID uuid.UUID, status string) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	query := conn(ctx, r.db).Where("endpoint_id = ?", endpointID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("created_at DESC").Limit(maxListedDeliveries).Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+type outboxRepo struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) repository.OutboxRepository {
+	return &outboxRepo{db: db}
+}
+
+func (r *outboxRepo) Add(ctx context.Context, event *models.OutboxEvent) error {
+	if err := conn(ctx, r.db).Create(event).Error; err != nil {
+		return fmt.Errorf("failed to add outbox event: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepo) ClaimPending(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.OutboxEvent, error) {
+	var ids []uuid.UUID
+	if err := conn(ctx, r.db).Raw(`
+		UPDATE outbox
+		SET attempts = attempts + 1,
+			next_attempt_at = @lease_until
+		WHERE id IN (
+			SELECT id
+			FROM outbox
+			WHERE published_at IS NULL
+				AND next_attempt_at <= @now
+			ORDER BY occurred_at
+			LIMIT @limit
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`, map[string]interface{}{
+		"now":         now,
+		"lease_until": leaseUntil,
+		"limit":       limit,
+	}).Scan(&ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var claimed []models.OutboxEvent
+	if err := conn(ctx, r.db).
+		Where("id IN ?", ids).
+		Order("occurred_at").
+		Find(&claimed).Error; err != nil {
+		return nil, fmt.Errorf("failed to load claimed outbox events: %w", err)
+	}
+	return claimed, nil
+}
+
+func (r *outboxRepo) MarkPublished(ctx context.Context, id uuid.UUID, publishedAt time.Time) error {
+	if err := conn(ctx, r.db).Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"published_at": publishedAt,
+			"last_error":   "",
+		}).Error; err != nil {
+		return fmt.Errorf("failed to mark outbox event published: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepo) MarkFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	if err := conn(ctx, r.db).Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_error":      lastError,
+			"next_attempt_at": nextAttemptAt,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepo) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("published_at < ?", before).
+		Delete(&models.OutboxEvent{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete published outbox events: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+type riskRepo struct {
+	db *gorm.DB
+}
+
+func NewRiskRepository(db *gorm.DB) repository.RiskRepository {
+	return &riskRepo{db: db}
+}
+
+func (r *riskRepo) ListActiveLicenses(ctx context.Context, since time.Time, types []string) ([]uuid.UUID, error) {
+	var licenseIDs []uuid.UUID
+	if err := conn(ctx, r.db).Model(&models.LicenseActivity{}).
+		Where("created_at >= ? AND activity_type IN ?", since, types).
+		Distinct("license_id").
+		Pluck("license_id", &licenseIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list active licenses: %w", err)
+	}
+	return licenseIDs, nil
+}
+
+func (r *riskRepo) ListActivity(ctx context.Context, licenseID uuid.UUID, since time.Time, types []string) ([]models.LicenseActivity, error) {
+	var activities []models.LicenseActivity
+	if err := conn(ctx, r.db).
+		Where("license_id = ? AND created_at >= ? AND activity_type IN ?", licenseID, since, types).
+		Order("created_at").
+		Find(&activities).Error; err != nil {
+		return nil, fmt.Errorf("failed to list license activity: %w", err)
+	}
+	return activities, nil
+}
+
+func (r *riskRepo) Save(ctx context.Context, report *models.RiskReport) error {
+	if err := conn(ctx, r.db).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(report).Error; err != nil {
+		return fmt.Errorf("failed to save risk report: %w", err)
+	}
+	return nil
+}
+
+func (r *riskRepo) Get(ctx context.Context, licenseID uuid.UUID) (*models.RiskReport, error) {
+	var report models.RiskReport
+	if err := conn(ctx, r.db).First(&report, "license_id = ?", licenseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get risk report: %w", err)
+	}
+	return &report, nil
+}
+
+func (r *riskRepo) Delete(ctx context.Context, licenseID uuid.UUID) error {
+	if err := conn(ctx, r.db).
+		Delete(&models.RiskReport{}, "license_id = ?", licenseID).Error; err != nil {
+		return fmt.Errorf("failed to delete risk report: %w", err)
+	}
+	return nil
+}
+
+func (r *riskRepo) List(ctx context.Context, applicationID uuid.UUID, minScore int) ([]models.RiskReport, error) {
+	var reports []models.RiskReport
+	if err := conn(ctx, r.db).
+		Joins("JOIN licenses ON licenses.id = license_risk_reports.license_id").
+		Where("licenses.application_id = ? AND license_risk_reports.score >= ?", applicationID, minScore).
+		Order("license_risk_reports.score DESC, license_risk_reports.analyzed_at DESC").
+		Find(&reports).Error; err != nil {
+		return nil, fmt.Errorf("failed to list risk reports: %w", err)
+	}
+	return reports, nil
+}
+
+func (r *riskRepo) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	result := conn(ctx, r.db).
+		Where("analyzed_at < ?", before).
+		Delete(&models.RiskReport{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete stale risk reports: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -2,11 +2,19 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/LywwKkA-aD/golicensemanager/internal/models"
 	"github.com/google/uuid"
 )
 
+// Transactor runs a function in a database transaction. Repository calls made
+// with the context passed to fn take part in the transaction, and nested calls
+// join the outer one. The transaction commits when fn returns nil.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 // ApplicationRepository handles database operations for applications
 type ApplicationRepository interface {
 	Create(ctx context.Context, app *models.Application) (*models.Application, error)
@@ -26,17 +34,109 @@ type LicenseTypeRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 }
 
+// FeatureDefinitionRepository handles database operations for the feature catalog
+type FeatureDefinitionRepository interface {
+	Create(ctx context.Context, feature *models.FeatureDefinition) (*models.FeatureDefinition, error)
+	GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.FeatureDefinition, error)
+	GetByKey(ctx context.Context, applicationID uuid.UUID, key string) (*models.FeatureDefinition, error)
+	List(ctx context.Context, applicationID uuid.UUID) ([]models.FeatureDefinition, error)
+	Update(ctx context.Context, feature *models.FeatureDefinition) (*models.FeatureDefinition, error)
+	Delete(ctx context.Context, applicationID, id uuid.UUID) error
+}
+
 // LicenseRepository handles database operations for licenses
 type LicenseRepository interface {
 	Create(ctx context.Context, license *models.License) (*models.License, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.License, error)
 	GetByKey(ctx context.Context, licenseKey string) (*models.License, error)
+	// GetForUpdate locks the license for the rest of the transaction. The
+	// associations are not loaded.
+	GetForUpdate(ctx context.Context, id uuid.UUID) (*models.License, error)
 	List(ctx context.Context, filters LicenseFilters) ([]models.License, error)
 	Update(ctx context.Context, license *models.License) (*models.License, error)
+	// UpdateTerm sets only the expiry date and active flag of the license
+	UpdateTerm(ctx context.Context, id uuid.UUID, expiryDate time.Time, isActive bool) error
+	// UpdatePlan sets only the license type, usage limits, term and active
+	// flag of the license
+	UpdatePlan(ctx context.Context, license *models.License) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	CreateActivity(ctx context.Context, activity *models.LicenseActivity) error
-	GetActivities(ctx context.Context, licenseID uuid.UUID) ([]models.LicenseActivity, error)
+	GetActivities(ctx context.Context, licenseID uuid.UUID, filters ActivityFilters) ([]models.LicenseActivity, error)
 	HasActiveClientLicenses(ctx context.Context, applicationID, clientID uuid.UUID) (bool, error)
+	// Reinstate clears the suspension of the license. It reports false when
+	// the license was not suspended, e.g. because another caller reinstated it.
+	Reinstate(ctx context.Context, id uuid.UUID) (bool, error)
+	ListDueReinstatements(ctx context.Context, before time.Time) ([]models.License, error)
+	// Transfer moves the licenses to another client in a single transaction,
+	// optionally removing their machine activations
+	Transfer(ctx context.Context, ids []uuid.UUID, toClientID uuid.UUID, resetActivations bool) error
+	// CreateBatch inserts groups of licenses in a single transaction and returns
+	// the error of each failed group. In atomic mode any failure rolls back all
+	// groups; otherwise only the failed groups are discarded.
+	CreateBatch(ctx context.Context, groups [][]*models.License, atomic bool) ([]error, error)
+	// IncrementUsage atomically adds delta to a usage counter, never going below
+	// zero, and returns the new value. With a limit, an increment that would
+	// exceed it fails with ErrLimitExceeded and the current value.
+	IncrementUsage(ctx context.Context, id uuid.UUID, metric string, delta float64, limit *float64) (float64, error)
+	// SetUsage atomically overwrites the given usage counters
+	SetUsage(ctx context.Context, id uuid.UUID, usage map[string]float64) error
+	UpdateLastCheck(ctx context.Context, id uuid.UUID, at time.Time) error
+	// Expire deactivates active licenses whose grace period ended by now and
+	// records an expiration activity for each, attributed to the principal.
+	// It returns the number of licenses deactivated.
+	Expire(ctx context.Context, now time.Time, principal string) (int64, error)
+}
+
+// LicenseAddonRepository handles database operations for license add-ons
+type LicenseAddonRepository interface {
+	// Create fails with ErrDuplicateKey when an add-on of the same license
+	// type is already attached to the license
+	Create(ctx context.Context, addon *models.LicenseAddon) (*models.LicenseAddon, error)
+	GetByID(ctx context.Context, licenseID, id uuid.UUID) (*models.LicenseAddon, error)
+	// GetByType returns the add-on of the given license type attached to the license
+	GetByType(ctx context.Context, licenseID, licenseTypeID uuid.UUID) (*models.LicenseAddon, error)
+	Update(ctx context.Context, addon *models.LicenseAddon) (*models.LicenseAddon, error)
+	Delete(ctx context.Context, licenseID, id uuid.UUID) error
+}
+
+// SubscriptionRepository handles database operations for subscriptions
+type SubscriptionRepository interface {
+	Create(ctx context.Context, subscription *models.Subscription) (*models.Subscription, error)
+	GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.Subscription, error)
+	// GetForUpdate returns the subscription and locks it until the surrounding
+	// transaction ends
+	GetForUpdate(ctx context.Context, id uuid.UUID) (*models.Subscription, error)
+	GetByLicense(ctx context.Context, licenseID uuid.UUID) (*models.Subscription, error)
+	List(ctx context.Context, applicationID uuid.UUID, status string) ([]models.Subscription, error)
+	Update(ctx context.Context, subscription *models.Subscription) (*models.Subscription, error)
+	// ListPeriodEnded returns the IDs of up to limit active subscriptions whose
+	// current period ended at or before now
+	ListPeriodEnded(ctx context.Context, now time.Time, limit int) ([]uuid.UUID, error)
+}
+
+// ActivationRepository handles database operations for machine activations
+type ActivationRepository interface {
+	// Activate registers the machine for the license, or refreshes an existing
+	// activation. It returns ErrLimitExceeded when a new activation would
+	// exceed maxActivations; a maxActivations of zero means unlimited.
+	Activate(ctx context.Context, activation *models.Activation, maxActivations int) (*models.Activation, error)
+	Deactivate(ctx context.Context, licenseID uuid.UUID, fingerprint string) error
+	GetByFingerprint(ctx context.Context, licenseID uuid.UUID, fingerprint string) (*models.Activation, error)
+	List(ctx context.Context, licenseID uuid.UUID) ([]models.Activation, error)
+	Touch(ctx context.Context, id uuid.UUID, ipAddress string) error
+}
+
+// LeaseRepository handles database operations for floating license leases
+type LeaseRepository interface {
+	// Checkout grants a lease on the license, or refreshes the holder's
+	// existing lease. It returns ErrLimitExceeded when maxLeases unexpired
+	// leases are already held by others.
+	Checkout(ctx context.Context, lease *models.LicenseLease, maxLeases int) (*models.LicenseLease, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.LicenseLease, error)
+	Renew(ctx context.Context, id uuid.UUID) (*models.LicenseLease, error)
+	Release(ctx context.Context, id uuid.UUID) error
+	ListActive(ctx context.Context, licenseID uuid.UUID) ([]models.LicenseLease, error)
+	DeleteExpired(ctx context.Context) (int64, error)
 }
 
 // ClientRepository handles database operations for clients
@@ -49,6 +149,93 @@ type ClientRepository interface {
 	ExistsByEmail(ctx context.Context, applicationID uuid.UUID, email string) (bool, error)
 }
 
+// UsageRepository handles database operations for periodic usage resets
+type UsageRepository interface {
+	// ListResettable returns the licenses with at least one usage reset period
+	ListResettable(ctx context.Context) ([]models.License, error)
+	// Reset stores the snapshot of a finished period, filling in the used and
+	// limit values, and zeroes the counter. It reports false when the period
+	// was already reset.
+	Reset(ctx context.Context, snapshot *models.UsageHistory) (bool, error)
+	ListHistory(ctx context.Context, licenseID uuid.UUID, metric string) ([]models.UsageHistory, error)
+}
+
+// ExpiryNoticeRepository handles database operations for expiry notices
+type ExpiryNoticeRepository interface {
+	// ListExpiring returns the active licenses with an expiry date after from
+	// and up to until
+	ListExpiring(ctx context.Context, from, until time.Time) ([]models.License, error)
+	// Claim records the notice unless one was already recorded for the same
+	// license, window and expiry date, and reports whether it was recorded
+	Claim(ctx context.Context, notice *models.ExpiryNotice) (bool, error)
+}
+
+// WebhookRepository handles database operations for webhook endpoints and
+// their deliveries
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error)
+	GetEndpoint(ctx context.Context, applicationID, id uuid.UUID) (*models.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context, applicationID uuid.UUID) ([]models.WebhookEndpoint, error)
+	// ListSubscribed returns the active endpoints of the application
+	// subscribed to the event type, directly or through "*"
+	ListSubscribed(ctx context.Context, applicationID uuid.UUID, eventType string) ([]models.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, applicationID, id uuid.UUID) error
+	// CreateDeliveries queues deliveries, skipping events already queued for
+	// the endpoint unless the delivery is a redelivery
+	CreateDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error
+	// ClaimDue locks up to limit due deliveries of active endpoints, counts
+	// the attempt and hides them from other callers until leaseUntil, so a
+	// crashed sender's deliveries are retried. Rows locked by concurrent
+	// callers are skipped. The deliveries come with their endpoint.
+	ClaimDue(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetDelivery(ctx context.Context, endpointID, id uuid.UUID) (*models.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, endpointID uuid.UUID, status string) ([]models.WebhookDelivery, error)
+}
+
+// OutboxRepository handles database operations for outbox events
+type OutboxRepository interface {
+	Add(ctx context.Context, event *models.OutboxEvent) error
+	// ClaimPending locks up to limit unpublished events that are due, in the
+	// order they occurred, counts the attempt and hides them from other
+	// callers until leaseUntil. Rows locked by concurrent callers are skipped.
+	ClaimPending(ctx context.Context, now, leaseUntil time.Time, limit int) ([]models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id uuid.UUID, publishedAt time.Time) error
+	MarkFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error
+	// DeletePublished removes events published before the given time
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
+
+// RiskRepository handles database operations for license risk reports
+type RiskRepository interface {
+	// ListActiveLicenses returns the licenses with activities of the given
+	// types since the given time
+	ListActiveLicenses(ctx context.Context, since time.Time, types []string) ([]uuid.UUID, error)
+	// ListActivity returns the activities of a license of the given types
+	// since the given time, ordered by time
+	ListActivity(ctx context.Context, licenseID uuid.UUID, since time.Time, types []string) ([]models.LicenseActivity, error)
+	// Save creates or replaces the report of a license
+	Save(ctx context.Context, report *models.RiskReport) error
+	Get(ctx context.Context, licenseID uuid.UUID) (*models.RiskReport, error)
+	Delete(ctx context.Context, licenseID uuid.UUID) error
+	List(ctx context.Context, applicationID uuid.UUID, minScore int) ([]models.RiskReport, error)
+	// DeleteStale removes reports not refreshed since the given time, i.e.
+	// of licenses without recent activity
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SigningKeyRepository handles database operations for signing keys
+type SigningKeyRepository interface {
+	Create(ctx context.Context, key *models.SigningKey) (*models.SigningKey, error)
+	GetByKeyID(ctx context.Context, kid string) (*models.SigningKey, error)
+	GetActive(ctx context.Context) (*models.SigningKey, error)
+	List(ctx context.Context, statuses ...string) ([]models.SigningKey, error)
+	Rotate(ctx context.Context, key *models.SigningKey) (*models.SigningKey, error)
+	UpdateStatus(ctx context.Context, kid, status string) error
+	UpdatePrivateKey(ctx context.Context, kid, privateKey string) error
+}
+
 // LicenseFilters defines the available filters for listing licenses
 type LicenseFilters struct {
 	ApplicationID uuid.UUID
@@ -57,6 +244,12 @@ type LicenseFilters struct {
 	IsRevoked     *bool
 }
 
+// ActivityFilters defines the available filters for listing license activities
+type ActivityFilters struct {
+	ActivityType string
+	IPAddress    string
+}
+
 // ClientFilters defines the available filters for listing clients
 type ClientFilters struct {
 	ApplicationID uuid.UUID
@@ -0,0 +1,37 @@
+// Package requestmeta carries metadata about the request being served through
+// a context, so services can record who did what without depending on the
+// HTTP layer.
+package requestmeta
+
+import "context"
+
+// Metadata describes the origin of a request
+type Metadata struct {
+	RequestID string
+	IPAddress string
+	UserAgent string
+	// Principal identifies the authenticated caller, e.g. "application:<id>",
+	// or the background job acting on its own, e.g. "job:usage-reset"
+	Principal string
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the metadata
+func NewContext(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, contextKey{}, md)
+}
+
+// FromContext returns the metadata carried by ctx, if any
+func FromContext(ctx context.Context) (Metadata, bool) {
+	md, ok := ctx.Value(contextKey{}).(Metadata)
+	return md, ok
+}
+
+// WithPrincipal returns a copy of ctx whose metadata names the principal,
+// keeping any other metadata already carried by ctx
+func WithPrincipal(ctx context.Context, principal string) context.Context {
+	md, _ := FromContext(ctx)
+	md.Principal = principal
+	return NewContext(ctx, md)
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+	"github.com/LywwKkA-aD/golicensemanager/pkg/geoip"
+)
+
+// analyzedActivityTypes are the activities caused by clients using a key
+var analyzedActivityTypes = []string{"validation", "activation", "lease_checkout"}
+
+// ipRiskReasons are based on client IP addresses, which a caller can spoof
+// or rotate at will; they are reported but never suspend a license
+var ipRiskReasons = []string{models.RiskReasonDistinctIPs, models.RiskReasonImpossibleTravel}
+
+const (
+	maxRiskScore = 100
+
+	// Trips shorter than this are within the accuracy of IP geolocation
+	minTravelDistanceKm = 200
+)
+
+// GeoLocator resolves IP addresses to coordinates for impossible travel
+// detection
+type GeoLocator interface {
+	Locate(ip string) (geoip.Location, bool)
+}
+
+// AnomalyConfig tunes the key sharing analysis. Zero thresholds disable the
+// corresponding check.
+type AnomalyConfig struct {
+	// Window is how far back activity is analyzed
+	Window                  time.Duration
+	MaxDistinctIPs          int
+	MaxDistinctFingerprints int
+	// BurstThreshold is the number of validations within BurstWindow above
+	// which a license is flagged
+	BurstWindow    time.Duration
+	BurstThreshold int
+	// MaxTravelSpeed is the fastest plausible travel between two requests in km/h
+	MaxTravelSpeed float64
+	// AutoSuspendScore suspends licenses scoring at least this much, not
+	// counting IP based reasons
+	AutoSuspendScore int
+}
+
+type AnomalyService interface {
+	// AnalyzeAll scores every license with recent activity and returns the
+	// number of flagged licenses
+	AnalyzeAll(ctx context.Context) (int, error)
+	// Analyze scores a single license now
+	Analyze(ctx context.Context, licenseID uuid.UUID) (*models.RiskReport, error)
+	GetReport(ctx context.Context, licenseID uuid.UUID) (*models.RiskReport, error)
+	List(ctx context.Context, applicationID uuid.UUID, minScore int) ([]models.RiskReport, error)
+}
+
+type anomalyService struct {
+	repo     repository.RiskRepository
+	licenses LicenseService
+	geo      GeoLocator
+	cfg      AnomalyConfig
+	logger   *zap.SugaredLogger
+}
+
+// NewAnomalyService creates the key sharing analyzer. Impossible travel
+// detection is disabled when geo is nil.
+func NewAnomalyService(
+	repo repository.RiskRepository,
+	licenses LicenseService,
+	geo GeoLocator,
+	cfg AnomalyConfig,
+	logger *zap.SugaredLogger,
+) AnomalyService {
+	return &anomalyService{
+		repo:     repo,
+		licenses: licenses,
+		geo:      geo,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+func (s *anomalyService) AnalyzeAll(ctx context.Context) (int, error) {
+	now := time.Now()
+	windowStart := now.Add(-s.cfg.Window)
+
+	licenseIDs, err := s.repo.ListActiveLicenses(ctx, windowStart, analyzedActivityTypes)
+	if err != nil {
+		return 0, err
+	}
+
+	// Load one license at a time to bound memory use
+	flagged := 0
+	for _, licenseID := range licenseIDs {
+		activities, err := s.repo.ListActivity(ctx, licenseID, windowStart, analyzedActivityTypes)
+		if err != nil {
+			return flagged, err
+		}
+
+		report, err := s.process(ctx, licenseID, activities, windowStart, now)
+		if err != nil {
+			return flagged, err
+		}
+		if report.Score > 0 {
+			flagged++
+		}
+	}
+
+	// Licenses without recent activity have nothing left to report
+	if _, err := s.repo.DeleteStale(ctx, now); err != nil {
+		return flagged, err
+	}
+
+	return flagged, nil
+}
+
+func (s *anomalyService) Analyze(ctx context.Context, licenseID uuid.UUID) (*models.RiskReport, error) {
+	if _, err := s.licenses.GetByID(ctx, licenseID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	windowStart := now.Add(-s.cfg.Window)
+
+	activities, err := s.repo.ListActivity(ctx, licenseID, windowStart, analyzedActivityTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.process(ctx, licenseID, activities, windowStart, now)
+}
+
+func (s *anomalyService) GetReport(ctx context.Context, licenseID uuid.UUID) (*models.RiskReport, error) {
+	report, err := s.repo.Get(ctx, licenseID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *anomalyService) List(ctx context.Context, applicationID uuid.UUID, minScore int) ([]models.RiskReport, error) {
+	return s.repo.List(ctx, applicationID, minScore)
+}
+
+// process scores the activities of a license, suspends it when configured to
+// and stores the report. Clean licenses keep no report.
+func (s *anomalyService) process(
+	ctx context.Context,
+	licenseID uuid.UUID,
+	activities []models.LicenseActivity,
+	windowStart, now time.Time,
+) (*models.RiskReport, error) {
+	report := s.score(activities)
+	report.LicenseID = licenseID
+	report.WindowStart = windowStart
+	report.WindowEnd = now
+	report.AnalyzedAt = now
+
+	previous, err := s.repo.Get(ctx, licenseID)
+	if err != nil && err != repository.ErrNotFound {
+		return nil, err
+	}
+
+	if report.Score == 0 {
+		if previous != nil {
+			return report, s.repo.Delete(ctx, licenseID)
+		}
+		return report, nil
+	}
+
+	if previous != nil {
+		report.SuspendedAt = previous.SuspendedAt
+	}
+	if s.cfg.AutoSuspendScore > 0 && suspensionScore(report) >= s.cfg.AutoSuspendScore {
+		if err := s.autoSuspend(ctx, report); err != nil {
+			s.logger.Warnf("Failed to suspend license %s with risk score %d: %v", licenseID, report.Score, err)
+		}
+	}
+
+	if err := s.repo.Save(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *anomalyService) autoSuspend(ctx context.Context, report *models.RiskReport) error {
+	// Suspend at most once per window, so a manual reinstatement sticks
+	if report.SuspendedAt != nil && report.SuspendedAt.After(report.WindowStart) {
+		return nil
+	}
+
+	license, err := s.licenses.GetByID(ctx, report.LicenseID)
+	if err != nil {
+		return err
+	}
+	if license.IsRevoked || license.IsSuspended {
+		return nil
+	}
+
+	reason := fmt.Sprintf("Suspected key sharing (risk score %d)", suspensionScore(report))
+	if _, err := s.licenses.Suspend(ctx, license.ID, reason, nil); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	report.SuspendedAt = &now
+	s.logger.Warnf("Suspended license %s: risk score %d", license.ID, report.Score)
+	return nil
+}
+
+// score rates how likely the activities come from a shared key
+func (s *anomalyService) score(activities []models.LicenseActivity) *models.RiskReport {
+	report := &models.RiskReport{Reasons: []models.RiskReason{}}
+
+	ips := make(map[string]bool)
+	fingerprints := make(map[string]bool)
+	var validations []time.Time
+	for _, activity := range activities {
+		if activity.IPAddress != "" {
+			ips[activity.IPAddress] = true
+		}
+		if fingerprint, ok := activity.Metadata["fingerprint"].(string); ok && fingerprint != "" {
+			fingerprints[fingerprint] = true
+		}
+		if activity.ActivityType == "validation" {
+			validations = append(validations, activity.CreatedAt)
+		}
+	}
+	report.DistinctIPs = len(ips)
+	report.DistinctFingerprints = len(fingerprints)
+
+	if excess := len(ips) - s.cfg.MaxDistinctIPs; s.cfg.MaxDistinctIPs > 0 && excess > 0 {
+		addRiskReason(report, models.RiskReasonDistinctIPs, min(20+5*(excess-1), 40),
+			"%d distinct IP addresses within %s (max %d)", len(ips), s.cfg.Window, s.cfg.MaxDistinctIPs)
+	}
+
+	if excess := len(fingerprints) - s.cfg.MaxDistinctFingerprints; s.cfg.MaxDistinctFingerprints > 0 && excess > 0 {
+		addRiskReason(report, models.RiskReasonDistinctFingerprints, min(25+10*(excess-1), 50),
+			"%d distinct machines within %s (max %d)", len(fingerprints), s.cfg.Window, s.cfg.MaxDistinctFingerprints)
+	}
+
+	if s.cfg.BurstThreshold > 0 && s.cfg.BurstWindow > 0 {
+		if peak := peakCount(validations, s.cfg.BurstWindow); peak > s.cfg.BurstThreshold {
+			addRiskReason(report, models.RiskReasonValidationBurst, min(20+10*(peak/s.cfg.BurstThreshold-1), 40),
+				"%d validations within %s (max %d)", peak, s.cfg.BurstWindow, s.cfg.BurstThreshold)
+		}
+	}
+
+	if s.geo != nil && s.cfg.MaxTravelSpeed > 0 {
+		if trips, km, elapsed := s.impossibleTravel(activities); trips > 0 {
+			addRiskReason(report, models.RiskReasonImpossibleTravel, min(40+10*(trips-1), 60),
+				"%d impossible trips, up to %.0f km in %s", trips, km, elapsed.Round(time.Second))
+		}
+	}
+
+	report.Score = min(report.Score, maxRiskScore)
+	return report
+}
+
+// impossibleTravel counts consecutive requests from locations too far apart
+// to travel between in the time that passed, and returns the longest of them
+func (s *anomalyService) impossibleTravel(activities []models.LicenseActivity) (int, float64, time.Duration) {
+	type sighting struct {
+		ip       string
+		location geoip.Location
+		at       time.Time
+	}
+
+	locations := make(map[string]*geoip.Location)
+	var last *sighting
+	var trips int
+	var longestKm float64
+	var longestElapsed time.Duration
+
+	for _, activity := range activities {
+		if activity.IPAddress == "" {
+			continue
+		}
+		location, seen := locations[activity.IPAddress]
+		if !seen {
+			if loc, ok := s.geo.Locate(activity.IPAddress); ok {
+				location = &loc
+			}
+			locations[activity.IPAddress] = location
+		}
+		if location == nil {
+			continue
+		}
+
+		if last != nil && last.ip != activity.IPAddress {
+			km := geoip.Distance(last.location, *location)
+			elapsed := activity.CreatedAt.Sub(last.at)
+			if km >= minTravelDistanceKm && (elapsed <= 0 || km/elapsed.Hours() > s.cfg.MaxTravelSpeed) {
+				trips++
+				if km > longestKm {
+					longestKm, longestElapsed = km, elapsed
+				}
+			}
+		}
+		last = &sighting{ip: activity.IPAddress, location: *location, at: activity.CreatedAt}
+	}
+
+	return trips, longestKm, longestElapsed
+}
+
+// Helper functions
+
+func addRiskReason(report *models.RiskReport, code string, points int, format string, args ...any) {
+	report.Reasons = append(report.Reasons, models.RiskReason{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		Points:  points,
+	})
+	report.Score += points
+}
+
+// suspensionScore is the part of the risk score that may suspend a license,
+// leaving out reasons based on client IP addresses
+func suspensionScore(report *models.RiskReport) int {
+	score := 0
+	for _, reason := range report.Reasons {
+		if !slices.Contains(ipRiskReasons, reason.Code) {
+			score += reason.Points
+		}
+	}
+	return min(score, maxRiskScore)
+}
+
+// peakCount returns the largest number of the sorted times falling within
+// any interval of the given length
+func peakCount(times []time.Time, window time.Duration) int {
+	peak := 0
+	start := 0
+	for end := range times {
+		for times[end].Sub(times[start]) >= window {
+			start++
+		}
+		peak = max(peak, end-start+1)
+	}
+	return peak
+}
@@ -27,12 +27,14 @@ type ApplicationService interface {
 
 type applicationService struct {
 	repo   repository.ApplicationRepository
+	keys   KeyService
 	logger *zap.SugaredLogger
 }
 
-func NewApplicationService(repo repository.ApplicationRepository, logger *zap.SugaredLogger) ApplicationService {
+func NewApplicationService(repo repository.ApplicationRepository, keys KeyService, logger *zap.SugaredLogger) ApplicationService {
 	return &applicationService{
 		repo:   repo,
+		keys:   keys,
 		logger: logger,
 	}
 }
@@ -113,15 +115,20 @@ func (s *applicationService) GenerateToken(ctx context.Context, apiKey, apiSecre
 		return "", err
 	}
 
-	// Create JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	kid, privateKey, err := s.keys.SigningKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Create JWT token signed with the active key
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
 		"application_id": app.ID.String(),
 		"api_key":        app.APIKey,
 		"exp":            time.Now().Add(24 * time.Hour).Unix(),
 	})
+	token.Header["kid"] = kid
 
-	// TODO: Get JWT secret from config
-	tokenString, err := token.SignedString([]byte("your-secret-key"))
+	tokenString, err := token.SignedString(privateKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -159,5 +166,11 @@ func validateApplication(app *models.Application) error {
 	if app.Name == "" {
 		return fmt.Errorf("%w: name is required", ErrInvalidInput)
 	}
+	if app.GracePeriodDays < 0 {
+		return fmt.Errorf("%w: grace period cannot be negative", ErrInvalidInput)
+	}
+	if _, err := NewKeyGenerator(app); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
 	return nil
 }
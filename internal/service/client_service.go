@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
 	"github.com/LywwKkA-aD/golicensemanager/internal/models"
 	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
 )
@@ -29,17 +30,23 @@ type ClientService interface {
 type clientService struct {
 	repo        repository.ClientRepository
 	licenseRepo repository.LicenseRepository
+	tx          repository.Transactor
+	publisher   events.Publisher
 	logger      *zap.SugaredLogger
 }
 
 func NewClientService(
 	repo repository.ClientRepository,
 	licenseRepo repository.LicenseRepository,
+	tx repository.Transactor,
+	publisher events.Publisher,
 	logger *zap.SugaredLogger,
 ) ClientService {
 	return &clientService{
 		repo:        repo,
 		licenseRepo: licenseRepo,
+		tx:          tx,
+		publisher:   publisher,
 		logger:      logger,
 	}
 }
@@ -65,7 +72,29 @@ func (s *clientService) Create(ctx context.Context, client *models.Client) (*mod
 	}
 	client.IsActive = true
 
-	return s.repo.Create(ctx, client)
+	// Store the client together with its event
+	var created *models.Client
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		created, err = s.repo.Create(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		event := events.New(events.ClientCreated, created.ApplicationID, created.ID, map[string]any{
+			"name":    created.Name,
+			"email":   created.Email,
+			"company": created.Company,
+		})
+		if err := s.publisher.Publish(ctx, event); err != nil {
+			return fmt.Errorf("failed to publish %s event: %w", event.Type, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
 }
 
 func (s *clientService) GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.Client, error) {
@@ -1,6 +1,9 @@
 package service
 
-import "errors"
+import (
+	"errors"
+	"strings"
+)
 
 var (
 	// Common errors
@@ -15,7 +18,28 @@ var (
 	ErrLicenseInvalid            = errors.New("license is invalid")
 	ErrLicenseExpired            = errors.New("license has expired")
 	ErrLicenseRevoked            = errors.New("license has been revoked")
+	ErrLicenseSuspended          = errors.New("license is suspended")
+	ErrLicenseNotSuspended       = errors.New("license is not suspended")
 	ErrLicenseUsageLimitExceeded = errors.New("license usage limit exceeded")
+	ErrMachineNotActivated       = errors.New("machine is not activated for this license")
+	ErrActivationLimitReached    = errors.New("license activation limit reached")
+	ErrLicenseNotFloating        = errors.New("license is not a floating license")
+	ErrNoLeaseAvailable          = errors.New("no floating license lease available")
+	ErrLeaseNotFound             = errors.New("lease not found or expired")
+	ErrLicenseNotTrial           = errors.New("license is not a trial")
+	ErrVersionNotAllowed         = errors.New("application version is not allowed by license")
+	ErrDuplicateAddon            = errors.New("add-on is already attached to the license")
+
+	// Feature catalog specific errors
+	ErrDuplicateFeature = errors.New("feature already exists")
+
+	// Signing key specific errors
+	ErrSigningKeyUnavailable = errors.New("signing key is not available")
+	ErrUnknownSigningKey     = errors.New("unknown signing key")
+
+	// Subscription specific errors
+	ErrDuplicateSubscription = errors.New("license already has a subscription")
+	ErrSubscriptionCanceled  = errors.New("subscription has been canceled")
 
 	// Client specific errors
 	ErrDuplicateEmail          = errors.New("email already exists")
@@ -26,3 +50,26 @@ var (
 	ErrFutureDate       = errors.New("date cannot be in the future")
 	ErrInvalidStatus    = errors.New("invalid status")
 )
+
+// FieldError describes a problem with a single input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError reports field-level input errors. It matches ErrInvalidInput.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return ErrInvalidInput.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+var featureKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,99}$`)
+
+// builtinFeatures are the feature keys interpreted by the server itself. They
+// are accepted even when an application's catalog does not declare them.
+var builtinFeatures = map[string]models.FeatureDefinition{
+	FeatureMaxActivations: {Key: FeatureMaxActivations, Type: models.FeatureTypeInt},
+	FeatureMaxLeases:      {Key: FeatureMaxLeases, Type: models.FeatureTypeInt},
+}
+
+type FeatureService interface {
+	Create(ctx context.Context, feature *models.FeatureDefinition) (*models.FeatureDefinition, error)
+	GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.FeatureDefinition, error)
+	List(ctx context.Context, applicationID uuid.UUID) ([]models.FeatureDefinition, error)
+	Update(ctx context.Context, feature *models.FeatureDefinition) (*models.FeatureDefinition, error)
+	Delete(ctx context.Context, applicationID, id uuid.UUID) error
+	// ValidateValues checks entitlement values against the application's
+	// catalog and reports field-level errors under the given field name.
+	// Applications without a catalog accept any values.
+	ValidateValues(ctx context.Context, applicationID uuid.UUID, field string, values map[string]any) error
+	// ApplyDefaults fills in catalog defaults for features missing from values
+	ApplyDefaults(ctx context.Context, applicationID uuid.UUID, values map[string]any) (map[string]any, error)
+}
+
+type featureService struct {
+	repo   repository.FeatureDefinitionRepository
+	logger *zap.SugaredLogger
+}
+
+func NewFeatureService(repo repository.FeatureDefinitionRepository, logger *zap.SugaredLogger) FeatureService {
+	return &featureService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *featureService) Create(ctx context.Context, feature *models.FeatureDefinition) (*models.FeatureDefinition, error) {
+	// Validate input
+	if err := validateFeatureDefinition(feature); err != nil {
+		return nil, err
+	}
+
+	// Check for duplicate key within the same application
+	if _, err := s.repo.GetByKey(ctx, feature.ApplicationID, feature.Key); err == nil {
+		return nil, ErrDuplicateFeature
+	} else if err != repository.ErrNotFound {
+		return nil, err
+	}
+
+	return s.repo.Create(ctx, feature)
+}
+
+func (s *featureService) GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.FeatureDefinition, error) {
+	feature, err := s.repo.GetByID(ctx, applicationID, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return feature, nil
+}
+
+func (s *featureService) List(ctx context.Context, applicationID uuid.UUID) ([]models.FeatureDefinition, error) {
+	return s.repo.List(ctx, applicationID)
+}
+
+func (s *featureService) Update(ctx context.Context, feature *models.FeatureDefinition) (*models.FeatureDefinition, error) {
+	// Validate input
+	if err := validateFeatureDefinition(feature); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetByID(ctx, feature.ApplicationID, feature.ID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	// Keys are referenced by license types and licenses, so they cannot change
+	if feature.Key != existing.Key {
+		return nil, &ValidationError{Fields: []FieldError{{Field: "key", Message: "cannot be changed"}}}
+	}
+
+	// Preserve certain fields
+	feature.CreatedAt = existing.CreatedAt
+
+	return s.repo.Update(ctx, feature)
+}
+
+func (s *featureService) Delete(ctx context.Context, applicationID, id uuid.UUID) error {
+	if err := s.repo.Delete(ctx, applicationID, id); err != nil {
+		if err == repository.ErrNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *featureService) ValidateValues(ctx context.Context, applicationID uuid.UUID, field string, values map[string]any) error {
+	catalog, err := s.catalog(ctx, applicationID)
+	if err != nil || catalog == nil {
+		return err
+	}
+
+	// Sort keys so errors are reported in a stable order
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var fieldErrs []FieldError
+	for _, key := range keys {
+		def, ok := catalog[key]
+		if !ok {
+			fieldErrs = append(fieldErrs, FieldError{
+				Field:   field + "." + key,
+				Message: "is not a known feature",
+			})
+			continue
+		}
+		if msg := checkFeatureValue(&def, values[key]); msg != "" {
+			fieldErrs = append(fieldErrs, FieldError{Field: field + "." + key, Message: msg})
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return &ValidationError{Fields: fieldErrs}
+	}
+	return nil
+}
+
+func (s *featureService) ApplyDefaults(ctx context.Context, applicationID uuid.UUID, values map[string]any) (map[string]any, error) {
+	features, err := s.repo.List(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if values == nil {
+		values = make(map[string]any)
+	}
+	for _, feature := range features {
+		if _, ok := values[feature.Key]; !ok && feature.DefaultValue != nil {
+			values[feature.Key] = feature.DefaultValue
+		}
+	}
+	return values, nil
+}
+
+// catalog returns the application's feature definitions by key, or nil when
+// the application has not defined a catalog
+func (s *featureService) catalog(ctx context.Context, applicationID uuid.UUID) (map[string]models.FeatureDefinition, error) {
+	features, err := s.repo.List(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(features) == 0 {
+		return nil, nil
+	}
+
+	catalog := make(map[string]models.FeatureDefinition, len(features)+len(builtinFeatures))
+	for key, def := range builtinFeatures {
+		catalog[key] = def
+	}
+	for _, feature := range features {
+		catalog[feature.Key] = feature
+	}
+	return catalog, nil
+}
+
+// Helper functions
+
+func validateFeatureDefinition(feature *models.FeatureDefinition) error {
+	var fieldErrs []FieldError
+
+	if feature.ApplicationID == uuid.Nil {
+		return fmt.Errorf("%w: application ID is required", ErrInvalidInput)
+	}
+	if !featureKeyPattern.MatchString(feature.Key) {
+		fieldErrs = append(fieldErrs, FieldError{
+			Field:   "key",
+			Message: "must start with a lowercase letter and contain only lowercase letters, digits and underscores",
+		})
+	}
+	if _, ok := builtinFeatures[feature.Key]; ok {
+		fieldErrs = append(fieldErrs, FieldError{Field: "key", Message: "is reserved"})
+	}
+
+	switch feature.Type {
+	case models.FeatureTypeBool, models.FeatureTypeInt, models.FeatureTypeString:
+		if len(feature.EnumValues) > 0 {
+			fieldErrs = append(fieldErrs, FieldError{Field: "enum_values", Message: "are only allowed for enum features"})
+		}
+	case models.FeatureTypeEnum:
+		if len(feature.EnumValues) == 0 {
+			fieldErrs = append(fieldErrs, FieldError{Field: "enum_values", Message: "are required for enum features"})
+		}
+	default:
+		fieldErrs = append(fieldErrs, FieldError{Field: "type", Message: "must be one of bool, int, enum, string"})
+	}
+
+	if len(fieldErrs) == 0 && feature.DefaultValue != nil {
+		if msg := checkFeatureValue(feature, feature.DefaultValue); msg != "" {
+			fieldErrs = append(fieldErrs, FieldError{Field: "default", Message: msg})
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return &ValidationError{Fields: fieldErrs}
+	}
+	return nil
+}
+
+// checkFeatureValue returns a message describing why the value does not match
+// the feature definition, or an empty string when it does
+func checkFeatureValue(def *models.FeatureDefinition, value any) string {
+	switch def.Type {
+	case models.FeatureTypeBool:
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+	case models.FeatureTypeInt:
+		switch v := value.(type) {
+		case int, int64:
+		case float64:
+			if v != math.Trunc(v) {
+				return "must be an integer"
+			}
+		default:
+			return "must be an integer"
+		}
+	case models.FeatureTypeString:
+		if _, ok := value.(string); !ok {
+			return "must be a string"
+		}
+	case models.FeatureTypeEnum:
+		str, ok := value.(string)
+		if ok {
+			for _, allowed := range def.EnumValues {
+				if str == allowed {
+					return ""
+				}
+			}
+		}
+		return fmt.Sprintf("must be one of %v", def.EnumValues)
+	}
+	return ""
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+// keyCacheTTL bounds how long verification keys are served from memory, so
+// rotations made by other replicas are picked up
+const keyCacheTTL = time.Minute
+
+// encryptedKeyPrefix marks private keys sealed with the key encryption key;
+// keys stored before encryption was introduced are plain base64
+const encryptedKeyPrefix = "v1:"
+
+type KeyService interface {
+	// EnsureActiveKey makes sure an active signing key exists. When the store
+	// is empty the given key is imported, or a new one is generated if it is nil.
+	// Private keys still stored in plaintext are encrypted on the way.
+	EnsureActiveKey(ctx context.Context, seedKey ed25519.PrivateKey) (*models.SigningKey, error)
+	List(ctx context.Context) ([]models.SigningKey, error)
+	Rotate(ctx context.Context) (*models.SigningKey, error)
+	Retire(ctx context.Context, kid string) error
+	VerificationKeys(ctx context.Context) ([]models.SigningKey, error)
+	PublicKey(ctx context.Context, kid string) (ed25519.PublicKey, error)
+	SigningKey(ctx context.Context) (string, ed25519.PrivateKey, error)
+}
+
+type keyService struct {
+	repo repository.SigningKeyRepository
+	// kek encrypts private keys at rest
+	kek    []byte
+	logger *zap.SugaredLogger
+
+	mu       sync.RWMutex
+	keys     map[string]ed25519.PublicKey
+	loadedAt time.Time
+}
+
+func NewKeyService(repo repository.SigningKeyRepository, kek []byte, logger *zap.SugaredLogger) KeyService {
+	return &keyService{
+		repo:   repo,
+		kek:    kek,
+		logger: logger,
+	}
+}
+
+func (s *keyService) EnsureActiveKey(ctx context.Context, seedKey ed25519.PrivateKey) (*models.SigningKey, error) {
+	if err := s.encryptPlaintextKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	active, err := s.repo.GetActive(ctx)
+	if err == nil {
+		return active, nil
+	}
+	if err != repository.ErrNotFound {
+		return nil, err
+	}
+
+	if seedKey == nil {
+		_, seedKey, err = ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+	}
+
+	key, err := s.newSigningKey(seedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err = s.repo.Create(ctx, key)
+	if err != nil {
+		// Another replica may have created the key concurrently
+		if active, getErr := s.repo.GetActive(ctx); getErr == nil {
+			return active, nil
+		}
+		return nil, err
+	}
+
+	s.logger.Infof("Created signing key %s", key.KeyID)
+	s.invalidate()
+	return key, nil
+}
+
+func (s *keyService) List(ctx context.Context) ([]models.SigningKey, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *keyService) Rotate(ctx context.Context) (*models.SigningKey, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	key, err := s.newSigningKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err = s.repo.Rotate(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Rotated signing key, new active key %s", key.KeyID)
+	s.invalidate()
+	return key, nil
+}
+
+func (s *keyService) Retire(ctx context.Context, kid string) error {
+	key, err := s.repo.GetByKeyID(ctx, kid)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	// The active key must be rotated out before it can be retired
+	if key.Status == models.SigningKeyStatusActive {
+		return fmt.Errorf("%w: cannot retire the active signing key", ErrInvalidStatus)
+	}
+
+	if err := s.repo.UpdateStatus(ctx, kid, models.SigningKeyStatusRetired); err != nil {
+		if err == repository.ErrNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+func (s *keyService) VerificationKeys(ctx context.Context) ([]models.SigningKey, error) {
+	return s.repo.List(ctx, models.SigningKeyStatusActive, models.SigningKeyStatusRetiring)
+}
+
+func (s *keyService) PublicKey(ctx context.Context, kid string) (ed25519.PublicKey, error) {
+	s.mu.RLock()
+	publicKey, ok := s.keys[kid]
+	fresh := time.Since(s.loadedAt) < keyCacheTTL
+	s.mu.RUnlock()
+
+	if ok && fresh {
+		return publicKey, nil
+	}
+
+	if err := s.reload(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	publicKey, ok = s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+	return publicKey, nil
+}
+
+func (s *keyService) SigningKey(ctx context.Context) (string, ed25519.PrivateKey, error) {
+	key, err := s.repo.GetActive(ctx)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return "", nil, ErrSigningKeyUnavailable
+		}
+		return "", nil, err
+	}
+
+	privateKey, err := s.openPrivateKey(key)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key.KeyID, privateKey, nil
+}
+
+// encryptPlaintextKeys seals private keys stored before encryption at rest
+// was introduced
+func (s *keyService) encryptPlaintextKeys(ctx context.Context) error {
+	keys, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if strings.HasPrefix(key.PrivateKey, encryptedKeyPrefix) {
+			continue
+		}
+
+		privateKey, err := s.openPrivateKey(&key)
+		if err != nil {
+			return err
+		}
+		sealed, err := s.sealPrivateKey(key.KeyID, privateKey)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.UpdatePrivateKey(ctx, key.KeyID, sealed); err != nil {
+			return err
+		}
+		s.logger.Infof("Encrypted signing key %s at rest", key.KeyID)
+	}
+
+	return nil
+}
+
+// sealPrivateKey encrypts a private key with the key encryption key. The key
+// ID is bound as additional data so ciphertexts cannot be swapped between rows.
+func (s *keyService) sealPrivateKey(kid string, privateKey ed25519.PrivateKey) (string, error) {
+	aead, err := s.aead()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, privateKey, []byte(kid))
+	return encryptedKeyPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openPrivateKey decrypts a stored private key, accepting plaintext keys
+// written before encryption at rest was introduced
+func (s *keyService) openPrivateKey(key *models.SigningKey) (ed25519.PrivateKey, error) {
+	encoded, encrypted := strings.CutPrefix(key.PrivateKey, encryptedKeyPrefix)
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s is corrupt", key.KeyID)
+	}
+
+	if encrypted {
+		aead, err := s.aead()
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) < aead.NonceSize() {
+			return nil, fmt.Errorf("signing key %s is corrupt", key.KeyID)
+		}
+		nonce, ciphertext := raw[:aead.NonceSize()], raw[aead.NonceSize():]
+		raw, err = aead.Open(nil, nonce, ciphertext, []byte(key.KeyID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt signing key %s: %w", key.KeyID, err)
+		}
+	}
+
+	if len(raw) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("signing key %s is corrupt", key.KeyID)
+	}
+	return ed25519.PrivateKey(raw), nil
+}
+
+func (s *keyService) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.kek)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *keyService) reload(ctx context.Context) error {
+	keys, err := s.VerificationKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	publicKeys := make(map[string]ed25519.PublicKey, len(keys))
+	for _, key := range keys {
+		raw, err := base64.StdEncoding.DecodeString(key.PublicKey)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			s.logger.Warnf("Skipping corrupt signing key %s", key.KeyID)
+			continue
+		}
+		publicKeys[key.KeyID] = ed25519.PublicKey(raw)
+	}
+
+	s.mu.Lock()
+	s.keys = publicKeys
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *keyService) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// Helper functions
+
+func (s *keyService) newSigningKey(privateKey ed25519.PrivateKey) (*models.SigningKey, error) {
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	kid := keyThumbprint(publicKey)
+
+	sealed, err := s.sealPrivateKey(kid, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		KeyID:      kid,
+		Algorithm:  "EdDSA",
+		PublicKey:  base64.StdEncoding.EncodeToString(publicKey),
+		PrivateKey: sealed,
+		Status:     models.SigningKeyStatusActive,
+	}, nil
+}
+
+// keyThumbprint derives a stable key ID from the public key
+func keyThumbprint(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
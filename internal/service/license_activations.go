@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+// FeatureMaxActivations is the license type feature limiting the number of activated machines
+const FeatureMaxActivations = "max_activations"
+
+type ActivationRequest struct {
+	ApplicationID      uuid.UUID `json:"-"`
+	LicenseKey         string    `json:"license_key" binding:"required"`
+	MachineFingerprint string    `json:"machine_fingerprint" binding:"required"`
+	Hostname           string    `json:"hostname"`
+	Platform           string    `json:"platform"`
+	IPAddress          string    `json:"-"`
+}
+
+func (s *licenseService) Activate(ctx context.Context, req ActivationRequest) (*models.Activation, error) {
+	if req.MachineFingerprint == "" {
+		return nil, fmt.Errorf("%w: machine fingerprint is required", ErrInvalidInput)
+	}
+
+	license, err := s.getByLicenseKey(ctx, req.ApplicationID, req.LicenseKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err := checkLicenseStatus(license); err != nil {
+		return nil, err
+	}
+
+	activation, err := s.activationRepo.Activate(ctx, &models.Activation{
+		LicenseID:          license.ID,
+		MachineFingerprint: req.MachineFingerprint,
+		Hostname:           req.Hostname,
+		Platform:           req.Platform,
+		IPAddress:          req.IPAddress,
+	}, maxActivations(license))
+	if err != nil {
+		switch err {
+		case repository.ErrLimitExceeded:
+			return nil, ErrActivationLimitReached
+		case repository.ErrNotFound:
+			return nil, ErrLicenseInvalid
+		}
+		return nil, err
+	}
+
+	// Record activation activity
+	activity := &models.LicenseActivity{
+		LicenseID:    license.ID,
+		ActivityType: "activation",
+		Description:  fmt.Sprintf("Machine activated: %s", req.MachineFingerprint),
+		Metadata: map[string]interface{}{
+			"fingerprint": req.MachineFingerprint,
+			"hostname":    req.Hostname,
+			"platform":    req.Platform,
+		},
+		IPAddress: req.IPAddress,
+	}
+	if err := s.RecordActivity(ctx, activity); err != nil {
+		s.logger.Warnf("Failed to record license activity: %v", err)
+	}
+
+	return activation, nil
+}
+
+func (s *licenseService) Deactivate(ctx context.Context, applicationID uuid.UUID, licenseKey, fingerprint string) error {
+	license, err := s.getByLicenseKey(ctx, applicationID, licenseKey)
+	if err != nil {
+		return err
+	}
+
+	if err := s.activationRepo.Deactivate(ctx, license.ID, fingerprint); err != nil {
+		if err == repository.ErrNotFound {
+			return ErrMachineNotActivated
+		}
+		return err
+	}
+
+	// Record deactivation activity
+	activity := &models.LicenseActivity{
+		LicenseID:    license.ID,
+		ActivityType: "deactivation",
+		Description:  fmt.Sprintf("Machine deactivated: %s", fingerprint),
+		Metadata: map[string]interface{}{
+			"fingerprint": fingerprint,
+		},
+	}
+	if err := s.RecordActivity(ctx, activity); err != nil {
+		s.logger.Warnf("Failed to record license activity: %v", err)
+	}
+
+	return nil
+}
+
+func (s *licenseService) ListActivations(ctx context.Context, id uuid.UUID) ([]models.Activation, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.activationRepo.List(ctx, id)
+}
+
+// Helper functions
+
+// maxActivations returns the activation limit for the license, preferring the
+// per-license override over the license type feature. Zero means unlimited.
+func maxActivations(license *models.License) int {
+	if license.MaxActivations != nil {
+		return *license.MaxActivations
+	}
+	limit, _ := featureInt(licenseFeatures(license), FeatureMaxActivations)
+	return limit
+}
+
+// featureInt reads an integer feature value, accepting the numeric types
+// produced by JSON decoding
+func featureInt(features map[string]any, key string) (int, bool) {
+	return intValue(features[key])
+}
+
+func intValue(value any) (int, bool) {
+	switch v := value.(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+// AddonRequest attaches an add-on license type to a license
+type AddonRequest struct {
+	LicenseTypeID uuid.UUID `json:"license_type_id" binding:"required"`
+	// Days defaults to the add-on type duration
+	Days int `json:"days"`
+}
+
+// AttachAddon attaches an add-on license type to a base license. The add-on
+// starts today and runs for its own duration, independently of the license.
+func (s *licenseService) AttachAddon(ctx context.Context, id uuid.UUID, req AddonRequest) (*models.LicenseAddon, error) {
+	if req.Days < 0 {
+		return nil, fmt.Errorf("%w: add-on period cannot be negative", ErrInvalidInput)
+	}
+
+	license, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if license.IsRevoked {
+		return nil, ErrLicenseRevoked
+	}
+
+	addonType, err := s.licenseTypeRepo.GetByID(ctx, req.LicenseTypeID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, fmt.Errorf("%w: license type not found", ErrInvalidInput)
+		}
+		return nil, err
+	}
+	if addonType.ApplicationID != license.ApplicationID {
+		return nil, fmt.Errorf("%w: license type belongs to another application", ErrInvalidInput)
+	}
+	if !addonType.IsAddon {
+		return nil, fmt.Errorf("%w: license type is not an add-on", ErrInvalidInput)
+	}
+	if !addonType.IsActive {
+		return nil, fmt.Errorf("%w: license type is not active", ErrInvalidInput)
+	}
+
+	if _, err := s.addonRepo.GetByType(ctx, license.ID, addonType.ID); err == nil {
+		return nil, ErrDuplicateAddon
+	} else if err != repository.ErrNotFound {
+		return nil, err
+	}
+
+	days := req.Days
+	if days == 0 {
+		days = addonType.DurationDays
+	}
+	now := time.Now()
+	addon := &models.LicenseAddon{
+		LicenseID:     license.ID,
+		LicenseTypeID: addonType.ID,
+		StartDate:     now,
+		ExpiryDate:    now.AddDate(0, 0, days),
+	}
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// A concurrent attach of the same add-on fails on the unique index
+		if _, err := s.addonRepo.Create(ctx, addon); err != nil {
+			if err == repository.ErrDuplicateKey {
+				return ErrDuplicateAddon
+			}
+			return err
+		}
+
+		// Record add-on activity
+		activity := &models.LicenseActivity{
+			LicenseID:    license.ID,
+			ActivityType: "addon_attached",
+			Description:  fmt.Sprintf("Add-on %s attached for %d days", addonType.Name, days),
+			Metadata: map[string]interface{}{
+				"addon_id":        addon.ID.String(),
+				"license_type_id": addonType.ID.String(),
+				"expiry_date":     addon.ExpiryDate.Format(time.DateOnly),
+			},
+		}
+		return s.RecordActivity(ctx, activity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	addon.LicenseType = *addonType
+	return addon, nil
+}
+
+// RenewAddon extends an add-on like Renew extends a license: from its current
+// expiry date while it is running, from today once it has expired.
+func (s *licenseService) RenewAddon(ctx context.Context, id, addonID uuid.UUID, days int) (*models.LicenseAddon, error) {
+	if days < 0 {
+		return nil, fmt.Errorf("%w: renewal period cannot be negative", ErrInvalidInput)
+	}
+
+	license, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if license.IsRevoked {
+		return nil, ErrLicenseRevoked
+	}
+
+	addon, err := s.addonRepo.GetByID(ctx, license.ID, addonID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if days == 0 {
+		days = addon.LicenseType.DurationDays
+	}
+	if days <= 0 {
+		return nil, fmt.Errorf("%w: add-on type has no duration, renewal period is required", ErrInvalidInput)
+	}
+
+	oldExpiry := addon.ExpiryDate
+	base := oldExpiry
+	if now := time.Now(); now.After(oldExpiry) {
+		base = now
+	}
+	addon.ExpiryDate = base.AddDate(0, 0, days)
+
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.addonRepo.Update(ctx, addon); err != nil {
+			return err
+		}
+
+		// Record add-on activity
+		activity := &models.LicenseActivity{
+			LicenseID:    license.ID,
+			ActivityType: "addon_renewed",
+			Description:  fmt.Sprintf("Add-on %s renewed for %d days", addon.LicenseType.Name, days),
+			Metadata: map[string]interface{}{
+				"addon_id":        addon.ID.String(),
+				"old_expiry_date": oldExpiry.Format(time.DateOnly),
+				"new_expiry_date": addon.ExpiryDate.Format(time.DateOnly),
+				"days":            days,
+			},
+		}
+		return s.RecordActivity(ctx, activity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return addon, nil
+}
+
+func (s *licenseService) DetachAddon(ctx context.Context, id, addonID uuid.UUID) error {
+	license, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	addon, err := s.addonRepo.GetByID(ctx, license.ID, addonID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.addonRepo.Delete(ctx, license.ID, addon.ID); err != nil {
+			if err == repository.ErrNotFound {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		// Record add-on activity
+		activity := &models.LicenseActivity{
+			LicenseID:    license.ID,
+			ActivityType: "addon_detached",
+			Description:  fmt.Sprintf("Add-on %s detached", addon.LicenseType.Name),
+			Metadata: map[string]interface{}{
+				"addon_id":        addon.ID.String(),
+				"license_type_id": addon.LicenseTypeID.String(),
+			},
+		}
+		return s.RecordActivity(ctx, activity)
+	})
+}
+
+// Helper functions
+
+// addonActive reports whether the add-on is within its validity period
+func addonActive(addon *models.LicenseAddon, at time.Time) bool {
+	return !at.Before(addon.StartDate) && !at.After(addon.ExpiryDate)
+}
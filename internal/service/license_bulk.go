@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+	"github.com/LywwKkA-aD/golicensemanager/pkg/licensekey"
+)
+
+// Bulk issuance modes
+const (
+	// BulkModeAtomic creates either all licenses or none
+	BulkModeAtomic = "atomic"
+	// BulkModePartial creates the valid rows and reports the failed ones
+	BulkModePartial = "partial"
+)
+
+// Bulk row statuses
+const (
+	BulkRowCreated    = "created"
+	BulkRowFailed     = "failed"
+	BulkRowRolledBack = "rolled_back"
+)
+
+// maxBulkLicenses caps the number of licenses issued by a single request
+const maxBulkLicenses = 5000
+
+type BulkIssueRow struct {
+	ClientID      uuid.UUID `json:"client_id"`
+	LicenseTypeID uuid.UUID `json:"license_type_id"`
+	Quantity      int       `json:"quantity"`
+}
+
+type BulkIssueRequest struct {
+	ApplicationID uuid.UUID
+	Mode          string
+	Rows          []BulkIssueRow
+}
+
+type BulkIssuedLicense struct {
+	ID         uuid.UUID `json:"id"`
+	LicenseKey string    `json:"license_key"`
+}
+
+type BulkRowResult struct {
+	Row           int                 `json:"row"`
+	ClientID      uuid.UUID           `json:"client_id"`
+	LicenseTypeID uuid.UUID           `json:"license_type_id"`
+	Quantity      int                 `json:"quantity"`
+	Status        string              `json:"status"`
+	Error         string              `json:"error,omitempty"`
+	Licenses      []BulkIssuedLicense `json:"licenses,omitempty"`
+}
+
+type BulkIssueResult struct {
+	Mode     string          `json:"mode"`
+	Created  int             `json:"created"`
+	Failed   int             `json:"failed"`
+	Rows     []BulkRowResult `json:"rows"`
+	Complete bool            `json:"complete"`
+}
+
+// BulkIssue creates licenses for every row in a single transaction. Rows that
+// fail validation or insertion are reported per row; in atomic mode any failure
+// means no licenses are created.
+func (s *licenseService) BulkIssue(ctx context.Context, req BulkIssueRequest) (*BulkIssueResult, error) {
+	if req.Mode == "" {
+		req.Mode = BulkModeAtomic
+	}
+	if req.Mode != BulkModeAtomic && req.Mode != BulkModePartial {
+		return nil, fmt.Errorf("%w: mode must be %q or %q", ErrInvalidInput, BulkModeAtomic, BulkModePartial)
+	}
+	if len(req.Rows) == 0 {
+		return nil, fmt.Errorf("%w: at least one row is required", ErrInvalidInput)
+	}
+
+	total := 0
+	for i := range req.Rows {
+		if req.Rows[i].Quantity == 0 {
+			req.Rows[i].Quantity = 1
+		}
+		if req.Rows[i].Quantity > 0 {
+			total += req.Rows[i].Quantity
+		}
+	}
+	if total > maxBulkLicenses {
+		return nil, fmt.Errorf("%w: at most %d licenses can be issued at once", ErrInvalidInput, maxBulkLicenses)
+	}
+
+	keyGen, err := s.keyGenerator(ctx, req.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BulkIssueResult{
+		Mode: req.Mode,
+		Rows: make([]BulkRowResult, len(req.Rows)),
+	}
+	groups := make([][]*models.License, len(req.Rows))
+
+	// Lookups are shared between rows, as volume deals repeat clients and types
+	clients := make(map[uuid.UUID]*models.Client)
+	licenseTypes := make(map[uuid.UUID]*models.LicenseType)
+
+	failed := false
+	for i, row := range req.Rows {
+		result.Rows[i] = BulkRowResult{
+			Row:           i + 1,
+			ClientID:      row.ClientID,
+			LicenseTypeID: row.LicenseTypeID,
+			Quantity:      row.Quantity,
+		}
+
+		licenses, err := s.prepareBulkRow(ctx, req.ApplicationID, row, keyGen, clients, licenseTypes)
+		if err != nil {
+			result.Rows[i].Status = BulkRowFailed
+			result.Rows[i].Error = err.Error()
+			failed = true
+			continue
+		}
+		groups[i] = licenses
+	}
+
+	// Nothing is written when an atomic batch already failed validation
+	if failed && req.Mode == BulkModeAtomic {
+		return s.finishBulkResult(result, groups, true), nil
+	}
+
+	// The licenses are stored together with their events
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		groupErrs, err := s.repo.CreateBatch(ctx, groups, req.Mode == BulkModeAtomic)
+		if err != nil {
+			return err
+		}
+
+		for i, groupErr := range groupErrs {
+			if groupErr != nil {
+				result.Rows[i].Status = BulkRowFailed
+				result.Rows[i].Error = groupErr.Error()
+				failed = true
+			}
+		}
+
+		result = s.finishBulkResult(result, groups, failed && req.Mode == BulkModeAtomic)
+		for i, row := range result.Rows {
+			if row.Status != BulkRowCreated {
+				continue
+			}
+			for _, license := range groups[i] {
+				if err := s.publish(ctx, events.LicenseCreated, license, licenseEventData(license)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *licenseService) prepareBulkRow(
+	ctx context.Context,
+	appID uuid.UUID,
+	row BulkIssueRow,
+	keyGen licensekey.Generator,
+	clients map[uuid.UUID]*models.Client,
+	licenseTypes map[uuid.UUID]*models.LicenseType,
+) ([]*models.License, error) {
+	if row.ClientID == uuid.Nil {
+		return nil, fmt.Errorf("%w: client ID is required", ErrInvalidInput)
+	}
+	if row.LicenseTypeID == uuid.Nil {
+		return nil, fmt.Errorf("%w: license type ID is required", ErrInvalidInput)
+	}
+	if row.Quantity < 1 {
+		return nil, fmt.Errorf("%w: quantity must be at least 1", ErrInvalidInput)
+	}
+
+	client, ok := clients[row.ClientID]
+	if !ok {
+		var err error
+		client, err = s.clientRepo.GetByID(ctx, appID, row.ClientID)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return nil, fmt.Errorf("%w: client not found", ErrInvalidInput)
+			}
+			return nil, err
+		}
+		clients[row.ClientID] = client
+	}
+	if !client.IsActive {
+		return nil, fmt.Errorf("%w: client is not active", ErrInvalidInput)
+	}
+
+	licenseType, ok := licenseTypes[row.LicenseTypeID]
+	if !ok {
+		var err error
+		licenseType, err = s.licenseTypeRepo.GetByID(ctx, row.LicenseTypeID)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return nil, fmt.Errorf("%w: license type not found", ErrInvalidInput)
+			}
+			return nil, err
+		}
+		licenseTypes[row.LicenseTypeID] = licenseType
+	}
+	if licenseType.ApplicationID != appID {
+		return nil, fmt.Errorf("%w: license type not found", ErrInvalidInput)
+	}
+	if !licenseType.IsActive {
+		return nil, fmt.Errorf("%w: license type is not active", ErrInvalidInput)
+	}
+	if licenseType.IsAddon {
+		return nil, fmt.Errorf("%w: add-on license types cannot be issued on their own", ErrInvalidInput)
+	}
+
+	startDate := time.Now()
+	licenses := make([]*models.License, row.Quantity)
+	for i := range licenses {
+		license := &models.License{
+			ApplicationID: appID,
+			LicenseTypeID: licenseType.ID,
+			ClientID:      client.ID,
+			StartDate:     startDate,
+			ExpiryDate:    startDate.AddDate(0, 0, licenseType.DurationDays),
+			UsageLimits:   licenseType.Features,
+			CurrentUsage:  make(map[string]interface{}),
+			IsActive:      true,
+		}
+
+		licenseKey, err := keyGen.Generate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate license key: %w", err)
+		}
+		license.LicenseKey = licenseKey
+
+		licenses[i] = license
+	}
+
+	return licenses, nil
+}
+
+// finishBulkResult fills in the issued licenses and totals of the report
+func (s *licenseService) finishBulkResult(result *BulkIssueResult, groups [][]*models.License, rolledBack bool) *BulkIssueResult {
+	for i := range result.Rows {
+		row := &result.Rows[i]
+		if row.Status == BulkRowFailed {
+			result.Failed++
+			continue
+		}
+		if rolledBack {
+			row.Status = BulkRowRolledBack
+			continue
+		}
+
+		row.Status = BulkRowCreated
+		row.Licenses = make([]BulkIssuedLicense, len(groups[i]))
+		for j, license := range groups[i] {
+			row.Licenses[j] = BulkIssuedLicense{ID: license.ID, LicenseKey: license.LicenseKey}
+		}
+		result.Created += len(groups[i])
+	}
+
+	result.Complete = result.Failed == 0
+	return result
+}
+
+// ParseBulkIssueCSV reads bulk issuance rows from CSV. The header must name
+// the client_id and license_type_id columns; quantity is optional and
+// defaults to one. Other columns are ignored.
+func ParseBulkIssueCSV(r io.Reader) ([]BulkIssueRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: CSV is empty", ErrInvalidInput)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"client_id", "license_type_id"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: CSV header is missing column %q", ErrInvalidInput, name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []BulkIssueRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+
+		clientID, err := uuid.Parse(field(record, "client_id"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid client_id", ErrInvalidInput, line)
+		}
+		licenseTypeID, err := uuid.Parse(field(record, "license_type_id"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid license_type_id", ErrInvalidInput, line)
+		}
+
+		quantity := 1
+		if value := field(record, "quantity"); value != "" {
+			quantity, err = strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: invalid quantity", ErrInvalidInput, line)
+			}
+		}
+
+		rows = append(rows, BulkIssueRow{
+			ClientID:      clientID,
+			LicenseTypeID: licenseTypeID,
+			Quantity:      quantity,
+		})
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/requestmeta"
+)
+
+// ExpireLicenses deactivates licenses whose grace period has ended, so client
+// deletion and is_active filters see them as lapsed. Renewing a deactivated
+// license re-activates it. It is safe to run on several replicas at once.
+func (s *licenseService) ExpireLicenses(ctx context.Context) (int64, error) {
+	md, _ := requestmeta.FromContext(ctx)
+	return s.repo.Expire(ctx, time.Now(), md.Principal)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+)
+
+// NotifyExpiring emits an expiring_soon event for every license inside one of
+// the notification windows, given in days before expiry. A license is notified
+// once per window and expiry date, so renewed licenses are notified again. A
+// license entering several windows at once only gets the narrowest one.
+func (s *licenseService) NotifyExpiring(ctx context.Context, windows []int) (int, error) {
+	windows = normalizeWindows(windows)
+	if len(windows) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	licenses, err := s.noticeRepo.ListExpiring(ctx, now, now.AddDate(0, 0, windows[len(windows)-1]))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range licenses {
+		license := &licenses[i]
+		daysLeft := int(math.Ceil(license.ExpiryDate.Sub(now).Hours() / 24))
+		idx := sort.SearchInts(windows, daysLeft)
+		if idx == len(windows) {
+			continue
+		}
+		window := windows[idx]
+
+		// The notice, its event and its activity are stored together
+		var claimed bool
+		err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+			notice := &models.ExpiryNotice{
+				LicenseID:  license.ID,
+				WindowDays: window,
+				ExpiryDate: license.ExpiryDate,
+			}
+			var err error
+			claimed, err = s.noticeRepo.Claim(ctx, notice)
+			if err != nil || !claimed {
+				return err
+			}
+
+			data := licenseEventData(license)
+			data["days_left"] = daysLeft
+			data["window_days"] = window
+			event := events.New(events.LicenseExpiringSoon, license.ApplicationID, license.ID, data)
+			if err := s.publisher.Publish(ctx, event); err != nil {
+				return fmt.Errorf("failed to publish expiry notice: %w", err)
+			}
+
+			// Record expiry notice activity
+			activity := &models.LicenseActivity{
+				LicenseID:    license.ID,
+				ActivityType: "expiry_notice",
+				Description:  fmt.Sprintf("License expires in %d days", daysLeft),
+				Metadata: map[string]interface{}{
+					"event_id":    event.ID.String(),
+					"window_days": window,
+					"expiry_date": license.ExpiryDate.Format(time.DateOnly),
+				},
+			}
+			return s.RecordActivity(ctx, activity)
+		})
+		if err != nil {
+			return sent, err
+		}
+		if claimed {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// Helper functions
+
+// normalizeWindows returns the positive windows, sorted and deduplicated
+func normalizeWindows(windows []int) []int {
+	normalized := make([]int, 0, len(windows))
+	for _, window := range windows {
+		if window > 0 {
+			normalized = append(normalized, window)
+		}
+	}
+	sort.Ints(normalized)
+
+	unique := normalized[:0]
+	for i, window := range normalized {
+		if i == 0 || window != normalized[i-1] {
+			unique = append(unique, window)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeWindows(t *testing.T) {
+	tests := []struct {
+		name    string
+		windows []int
+		want    []int
+	}{
+		{name: "sorted", windows: []int{30, 7, 1}, want: []int{1, 7, 30}},
+		{name: "deduplicated", windows: []int{7, 30, 7, 1, 30}, want: []int{1, 7, 30}},
+		{name: "zero and negative windows dropped", windows: []int{0, 14, -3, 3}, want: []int{3, 14}},
+		{name: "already normalized", windows: []int{1, 7}, want: []int{1, 7}},
+		{name: "no positive windows", windows: []int{0, -1}, want: []int{}},
+		{name: "empty", windows: nil, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeWindows(tt.windows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeWindows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+)
+
+// Feature override operations
+const (
+	FeatureOpAdd    = "add"
+	FeatureOpChange = "change"
+	FeatureOpRemove = "remove"
+	FeatureOpReset  = "reset"
+)
+
+// Feature sources reported by validation
+const (
+	FeatureSourceType    = "type"
+	FeatureSourceLicense = "license"
+	FeatureSourceAddon   = "addon"
+)
+
+// FeatureOperation changes a single feature of a license
+type FeatureOperation struct {
+	// Op is add (new feature), change (new value), remove (drop a type feature)
+	// or reset (discard the license override)
+	Op      string `json:"op" binding:"required"`
+	Feature string `json:"feature" binding:"required"`
+	Value   any    `json:"value"`
+}
+
+// UpdateFeatures applies feature override operations to a license. The
+// operations are applied in order and either all succeed or none do.
+func (s *licenseService) UpdateFeatures(ctx context.Context, id uuid.UUID, ops []FeatureOperation) (*models.License, error) {
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("%w: at least one operation is required", ErrInvalidInput)
+	}
+
+	license, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]any, len(license.FeatureOverrides))
+	for key, value := range license.FeatureOverrides {
+		overrides[key] = value
+	}
+
+	var fieldErrs []FieldError
+	for i, op := range ops {
+		features, _ := mergeFeatures(license.LicenseType.Features, overrides)
+		_, exists := features[op.Feature]
+		field := fmt.Sprintf("operations[%d]", i)
+
+		switch op.Op {
+		case FeatureOpAdd, FeatureOpChange:
+			if op.Op == FeatureOpAdd && exists {
+				fieldErrs = append(fieldErrs, FieldError{Field: field, Message: "feature already exists, use change"})
+				continue
+			}
+			if op.Op == FeatureOpChange && !exists {
+				fieldErrs = append(fieldErrs, FieldError{Field: field, Message: "feature does not exist, use add"})
+				continue
+			}
+			if op.Value == nil {
+				fieldErrs = append(fieldErrs, FieldError{Field: field, Message: "value is required"})
+				continue
+			}
+			overrides[op.Feature] = op.Value
+		case FeatureOpRemove:
+			if !exists {
+				fieldErrs = append(fieldErrs, FieldError{Field: field, Message: "feature does not exist"})
+				continue
+			}
+			if _, fromType := license.LicenseType.Features[op.Feature]; fromType {
+				overrides[op.Feature] = nil
+			} else {
+				delete(overrides, op.Feature)
+			}
+		case FeatureOpReset:
+			if _, ok := overrides[op.Feature]; !ok {
+				fieldErrs = append(fieldErrs, FieldError{Field: field, Message: "feature has no override"})
+				continue
+			}
+			delete(overrides, op.Feature)
+		default:
+			fieldErrs = append(fieldErrs, FieldError{Field: field, Message: "op must be one of add, change, remove, reset"})
+		}
+	}
+	if len(fieldErrs) > 0 {
+		return nil, &ValidationError{Fields: fieldErrs}
+	}
+
+	// Removals are stored as nulls, which the catalog has no type for
+	values := make(map[string]any, len(overrides))
+	for key, value := range overrides {
+		if value != nil {
+			values[key] = value
+		}
+	}
+	if err := s.features.ValidateValues(ctx, license.ApplicationID, "feature_overrides", values); err != nil {
+		return nil, err
+	}
+
+	license.FeatureOverrides = overrides
+
+	// The overrides, their activity and their event are stored together
+	var updated *models.License
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		updated, err = s.repo.Update(ctx, license)
+		if err != nil {
+			return err
+		}
+
+		// Record feature override activity
+		activity := &models.LicenseActivity{
+			LicenseID:    license.ID,
+			ActivityType: "feature_override",
+			Description:  fmt.Sprintf("License features updated (%d operations)", len(ops)),
+			Metadata: map[string]interface{}{
+				"operations": ops,
+			},
+		}
+		if err := s.RecordActivity(ctx, activity); err != nil {
+			return err
+		}
+
+		data := licenseEventData(license)
+		data["feature_overrides"] = overrides
+		return s.publish(ctx, events.LicenseFeaturesUpdated, license, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// Helper functions
+
+// mergeFeatures applies license overrides to the license type features and
+// reports where each resulting value came from. A nil override removes the
+// feature.
+func mergeFeatures(typeFeatures, overrides map[string]any) (map[string]any, map[string]string) {
+	features := make(map[string]any, len(typeFeatures)+len(overrides))
+	sources := make(map[string]string, len(typeFeatures)+len(overrides))
+
+	for key, value := range typeFeatures {
+		features[key] = value
+		sources[key] = FeatureSourceType
+	}
+	for key, value := range overrides {
+		if value == nil {
+			delete(features, key)
+			delete(sources, key)
+			continue
+		}
+		features[key] = value
+		sources[key] = FeatureSourceLicense
+	}
+
+	return features, sources
+}
+
+// licenseEntitlements merges the license features with those of its add-ons
+// that are active at the given time. Add-ons only widen what the license
+// grants: booleans are or-ed, numbers take the larger limit and other values
+// are only added when the license lacks the feature.
+func licenseEntitlements(license *models.License, licenseType *models.LicenseType, at time.Time) (map[string]any, map[string]string) {
+	features, sources := mergeFeatures(licenseType.Features, license.FeatureOverrides)
+
+	for i := range license.Addons {
+		addon := &license.Addons[i]
+		if !addonActive(addon, at) {
+			continue
+		}
+		for key, value := range addon.LicenseType.Features {
+			current, exists := features[key]
+			if exists && !widensFeature(key, current, value) {
+				continue
+			}
+			features[key] = value
+			sources[key] = FeatureSourceAddon
+		}
+	}
+
+	return features, sources
+}
+
+// licenseFeatures returns the effective features of a license with a
+// preloaded license type and add-ons
+func licenseFeatures(license *models.License) map[string]any {
+	features, _ := licenseEntitlements(license, &license.LicenseType, time.Now())
+	return features
+}
+
+// widensFeature reports whether an add-on value grants more than the current one
+func widensFeature(key string, current, value any) bool {
+	if c, ok := current.(bool); ok {
+		v, ok := value.(bool)
+		return ok && v && !c
+	}
+
+	c, cok := intValue(current)
+	v, vok := intValue(value)
+	if !cok || !vok {
+		return false
+	}
+	// An activation limit of zero means unlimited
+	if key == FeatureMaxActivations && (c == 0 || v == 0) {
+		return c != 0
+	}
+	return v > c
+}
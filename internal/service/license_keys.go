@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+	"github.com/LywwKkA-aD/golicensemanager/pkg/licensekey"
+)
+
+// keyFormatCacheTTL bounds how long application key formats are served from
+// memory, so format changes made through other replicas are picked up
+const keyFormatCacheTTL = time.Minute
+
+type cachedKeyGenerator struct {
+	generator licensekey.Generator
+	loadedAt  time.Time
+}
+
+// NewKeyGenerator returns the license key generator configured for the application
+func NewKeyGenerator(app *models.Application) (licensekey.Generator, error) {
+	switch app.KeyFormat {
+	case "", models.KeyFormatHex:
+		return licensekey.Hex{}, nil
+	case models.KeyFormatGrouped:
+		return licensekey.NewGrouped(app.KeyPrefix, app.KeyGroups, app.KeyGroupSize, app.KeyAlphabet)
+	default:
+		return nil, fmt.Errorf("unknown key format %q", app.KeyFormat)
+	}
+}
+
+// keyGenerator returns the key generator of the application, cached in memory
+func (s *licenseService) keyGenerator(ctx context.Context, appID uuid.UUID) (licensekey.Generator, error) {
+	s.keyGenMu.RLock()
+	cached, ok := s.keyGens[appID]
+	s.keyGenMu.RUnlock()
+
+	if ok && time.Since(cached.loadedAt) < keyFormatCacheTTL {
+		return cached.generator, nil
+	}
+
+	app, err := s.appRepo.GetByID(ctx, appID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, fmt.Errorf("%w: application not found", ErrInvalidInput)
+		}
+		return nil, err
+	}
+
+	generator, err := NewKeyGenerator(app)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key format for application %s: %w", appID, err)
+	}
+
+	s.keyGenMu.Lock()
+	s.keyGens[appID] = cachedKeyGenerator{generator: generator, loadedAt: time.Now()}
+	s.keyGenMu.Unlock()
+
+	return generator, nil
+}
+
+// getByLicenseKey looks up a license by a key entered by a user. The key is
+// canonicalized with the application's current format, and mistyped keys are
+// rejected with the checksum error without a lookup. Keys that do not have the
+// layout of the current format are looked up as entered, since they may have
+// been issued before the application changed its key format.
+func (s *licenseService) getByLicenseKey(ctx context.Context, appID uuid.UUID, licenseKey string) (*models.License, error) {
+	normalized, checkErr := s.normalizeLicenseKey(ctx, appID, licenseKey)
+	switch {
+	case checkErr == nil:
+		licenseKey = normalized
+	case !errors.Is(checkErr, licensekey.ErrInvalidFormat):
+		return nil, checkErr
+	}
+
+	license, err := s.repo.GetByKey(ctx, licenseKey)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			if checkErr != nil {
+				return nil, checkErr
+			}
+			return nil, ErrLicenseInvalid
+		}
+		return nil, err
+	}
+	return license, nil
+}
+
+// normalizeLicenseKey canonicalizes a key entered by a user and rejects keys
+// that fail the application's format or checksum. Legacy hex keys and keys
+// of applications without a checksummed format are passed through unchanged.
+func (s *licenseService) normalizeLicenseKey(ctx context.Context, appID uuid.UUID, licenseKey string) (string, error) {
+	if appID == uuid.Nil || licensekey.IsLegacy(licenseKey) {
+		return licenseKey, nil
+	}
+
+	generator, err := s.keyGenerator(ctx, appID)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := generator.(licensekey.Hex); ok {
+		return licenseKey, nil
+	}
+
+	licenseKey = generator.Normalize(licenseKey)
+	if err := generator.Check(licenseKey); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrLicenseInvalid, err)
+	}
+	return licenseKey, nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+// FeatureMaxLeases is the license type feature that turns a license into a
+// floating license with at most this many concurrent leases
+const FeatureMaxLeases = "max_leases"
+
+const (
+	defaultLeaseTTL = 5 * time.Minute
+	minLeaseTTL     = 30 * time.Second
+	maxLeaseTTL     = 24 * time.Hour
+)
+
+type LeaseRequest struct {
+	ApplicationID uuid.UUID `json:"-"`
+	LicenseKey    string    `json:"license_key" binding:"required"`
+	Holder        string    `json:"holder" binding:"required"`
+	TTLSeconds    int       `json:"ttl_seconds"`
+	IPAddress     string    `json:"-"`
+}
+
+func (s *licenseService) CheckoutLease(ctx context.Context, req LeaseRequest) (*models.LicenseLease, error) {
+	if req.Holder == "" {
+		return nil, fmt.Errorf("%w: holder is required", ErrInvalidInput)
+	}
+
+	ttl, err := leaseTTL(req.TTLSeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	license, err := s.getByLicenseKey(ctx, req.ApplicationID, req.LicenseKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err := checkLicenseStatus(license); err != nil {
+		return nil, err
+	}
+
+	limit, ok := maxLeases(license)
+	if !ok {
+		return nil, ErrLicenseNotFloating
+	}
+
+	lease, err := s.leaseRepo.Checkout(ctx, &models.LicenseLease{
+		LicenseID:  license.ID,
+		Holder:     req.Holder,
+		IPAddress:  req.IPAddress,
+		TTLSeconds: int(ttl / time.Second),
+	}, limit)
+	if err != nil {
+		switch err {
+		case repository.ErrLimitExceeded:
+			return nil, ErrNoLeaseAvailable
+		case repository.ErrNotFound:
+			return nil, ErrLicenseInvalid
+		}
+		return nil, err
+	}
+
+	// Record checkout activity
+	activity := &models.LicenseActivity{
+		LicenseID:    license.ID,
+		ActivityType: "lease_checkout",
+		Description:  fmt.Sprintf("Lease checked out by %s", req.Holder),
+		Metadata: map[string]interface{}{
+			"lease_id": lease.ID.String(),
+			"holder":   req.Holder,
+		},
+		IPAddress: req.IPAddress,
+	}
+	if err := s.RecordActivity(ctx, activity); err != nil {
+		s.logger.Warnf("Failed to record license activity: %v", err)
+	}
+
+	return lease, nil
+}
+
+func (s *licenseService) HeartbeatLease(ctx context.Context, leaseID uuid.UUID) (*models.LicenseLease, error) {
+	lease, err := s.leaseRepo.GetByID(ctx, leaseID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrLeaseNotFound
+		}
+		return nil, err
+	}
+
+	// Stop renewing leases once the license itself is no longer usable
+	license, err := s.GetByID(ctx, lease.LicenseID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := checkLicenseStatus(license); err != nil {
+		return nil, err
+	}
+
+	renewed, err := s.leaseRepo.Renew(ctx, leaseID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrLeaseNotFound
+		}
+		return nil, err
+	}
+	return renewed, nil
+}
+
+func (s *licenseService) ReleaseLease(ctx context.Context, leaseID uuid.UUID) error {
+	lease, err := s.leaseRepo.GetByID(ctx, leaseID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return ErrLeaseNotFound
+		}
+		return err
+	}
+
+	if err := s.leaseRepo.Release(ctx, leaseID); err != nil {
+		if err == repository.ErrNotFound {
+			return ErrLeaseNotFound
+		}
+		return err
+	}
+
+	// Record release activity
+	activity := &models.LicenseActivity{
+		LicenseID:    lease.LicenseID,
+		ActivityType: "lease_release",
+		Description:  fmt.Sprintf("Lease released by %s", lease.Holder),
+		Metadata: map[string]interface{}{
+			"lease_id": lease.ID.String(),
+			"holder":   lease.Holder,
+		},
+	}
+	if err := s.RecordActivity(ctx, activity); err != nil {
+		s.logger.Warnf("Failed to record license activity: %v", err)
+	}
+
+	return nil
+}
+
+func (s *licenseService) ListLeases(ctx context.Context, id uuid.UUID) ([]models.LicenseLease, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.leaseRepo.ListActive(ctx, id)
+}
+
+func (s *licenseService) ExpireLeases(ctx context.Context) (int64, error) {
+	return s.leaseRepo.DeleteExpired(ctx)
+}
+
+// Helper functions
+
+// maxLeases returns the concurrent lease limit for the license, preferring the
+// per-license override. It reports false for licenses that are not floating.
+func maxLeases(license *models.License) (int, bool) {
+	if license.MaxLeases != nil {
+		return *license.MaxLeases, *license.MaxLeases > 0
+	}
+	limit, ok := featureInt(licenseFeatures(license), FeatureMaxLeases)
+	return limit, ok && limit > 0
+}
+
+func leaseTTL(seconds int) (time.Duration, error) {
+	if seconds == 0 {
+		return defaultLeaseTTL, nil
+	}
+
+	ttl := time.Duration(seconds) * time.Second
+	if ttl < minLeaseTTL || ttl > maxLeaseTTL {
+		return 0, fmt.Errorf("%w: ttl_seconds must be between %d and %d",
+			ErrInvalidInput, int(minLeaseTTL/time.Second), int(maxLeaseTTL/time.Second))
+	}
+	return ttl, nil
+}
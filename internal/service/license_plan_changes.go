@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+type PlanChangeRequest struct {
+	LicenseTypeID uuid.UUID `json:"license_type_id" binding:"required"`
+	// RestartTerm starts a new full term on the new plan instead of keeping
+	// the remaining term of the current one
+	RestartTerm bool `json:"restart_term"`
+}
+
+type PlanChange struct {
+	License          *models.License `json:"license"`
+	OldLicenseTypeID uuid.UUID       `json:"old_license_type_id"`
+	NewLicenseTypeID uuid.UUID       `json:"new_license_type_id"`
+	// Direction is upgrade, downgrade or lateral, by comparing plan prices
+	Direction      string  `json:"direction"`
+	RemainingDays  int     `json:"remaining_days"`
+	ProratedCredit float64 `json:"prorated_credit"`
+	Charge         float64 `json:"charge"`
+	AmountDue      float64 `json:"amount_due"`
+}
+
+// ChangePlan switches the license to another license type of the same
+// application. Usage limits are recomputed from the new type, keeping limits
+// set on the license itself, and the unused part of the current term is
+// credited against the price of the new plan. Licenses billed by a
+// subscription keep their term, which follows the subscription period.
+func (s *licenseService) ChangePlan(ctx context.Context, id uuid.UUID, req PlanChangeRequest) (*PlanChange, error) {
+	var change *PlanChange
+
+	// The license is locked while the change is computed and stored, so
+	// concurrent renewals and plan changes do not overwrite each other
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		license, err := s.lockLicense(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if license.IsRevoked {
+			return ErrLicenseRevoked
+		}
+		if license.LicenseTypeID == req.LicenseTypeID {
+			return fmt.Errorf("%w: license is already on this license type", ErrInvalidInput)
+		}
+
+		newType, err := s.licenseTypeRepo.GetByID(ctx, req.LicenseTypeID)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return fmt.Errorf("%w: license type not found", ErrInvalidInput)
+			}
+			return err
+		}
+		if newType.ApplicationID != license.ApplicationID {
+			return fmt.Errorf("%w: license type belongs to another application", ErrInvalidInput)
+		}
+		if newType.IsTrial {
+			return fmt.Errorf("%w: cannot change plan to a trial license type", ErrInvalidInput)
+		}
+		if newType.IsAddon {
+			return fmt.Errorf("%w: cannot change plan to an add-on license type", ErrInvalidInput)
+		}
+		if !newType.IsActive {
+			return fmt.Errorf("%w: license type is not active", ErrInvalidInput)
+		}
+
+		if req.RestartTerm {
+			subscription, err := s.subscriptionRepo.GetByLicense(ctx, license.ID)
+			if err != nil && err != repository.ErrNotFound {
+				return err
+			}
+			if subscription != nil && subscription.Status != models.SubscriptionCanceled {
+				return fmt.Errorf("%w: the term of a subscribed license follows its subscription and cannot be restarted", ErrInvalidInput)
+			}
+		}
+
+		oldType, err := s.licenseTypeRepo.GetByID(ctx, license.LicenseTypeID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		change = quotePlanChange(oldType, newType, license.ExpiryDate, now, req.RestartTerm)
+
+		license.LicenseTypeID = newType.ID
+		license.UsageLimits = planUsageLimits(license.UsageLimits, oldType, newType)
+		if req.RestartTerm {
+			license.StartDate = now
+			license.ExpiryDate = now.AddDate(0, 0, newType.DurationDays)
+		}
+
+		if err := s.repo.UpdatePlan(ctx, license); err != nil {
+			return err
+		}
+
+		// Record plan change activity
+		activity := &models.LicenseActivity{
+			LicenseID:    license.ID,
+			ActivityType: "plan_change",
+			Description:  fmt.Sprintf("License %s from %s to %s", change.Direction, oldType.Name, newType.Name),
+			Metadata: map[string]interface{}{
+				"old_license_type_id": oldType.ID.String(),
+				"new_license_type_id": newType.ID.String(),
+				"direction":           change.Direction,
+				"restart_term":        req.RestartTerm,
+				"remaining_days":      change.RemainingDays,
+				"prorated_credit":     change.ProratedCredit,
+				"charge":              change.Charge,
+				"amount_due":          change.AmountDue,
+				"expiry_date":         license.ExpiryDate.Format(time.DateOnly),
+			},
+		}
+		if err := s.RecordActivity(ctx, activity); err != nil {
+			return err
+		}
+
+		data := licenseEventData(license)
+		data["old_license_type_id"] = oldType.ID.String()
+		data["direction"] = change.Direction
+		data["amount_due"] = change.AmountDue
+		return s.publish(ctx, events.LicensePlanChanged, license, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	change.License, err = s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// Helper functions
+
+// quotePlanChange prices a move from oldType to newType for a license
+// expiring at expiry. The unused part of the current term is credited, and the
+// new plan is charged for the rest of that term or, when restarting, for a
+// full term.
+func quotePlanChange(oldType, newType *models.LicenseType, expiry, now time.Time, restartTerm bool) *PlanChange {
+	change := &PlanChange{
+		OldLicenseTypeID: oldType.ID,
+		NewLicenseTypeID: newType.ID,
+		Direction:        "lateral",
+		RemainingDays:    remainingDays(expiry, now, oldType.DurationDays),
+	}
+	switch {
+	case newType.Price > oldType.Price:
+		change.Direction = "upgrade"
+	case newType.Price < oldType.Price:
+		change.Direction = "downgrade"
+	}
+
+	// Credit the unused part of the current term
+	if oldType.DurationDays > 0 {
+		change.ProratedCredit = roundCents(oldType.Price * float64(change.RemainingDays) / float64(oldType.DurationDays))
+	}
+
+	// Charge the new plan for the term it will cover
+	if restartTerm {
+		change.Charge = roundCents(newType.Price)
+	} else if newType.DurationDays > 0 {
+		change.Charge = roundCents(newType.Price * float64(change.RemainingDays) / float64(newType.DurationDays))
+	}
+	change.AmountDue = roundCents(change.Charge - change.ProratedCredit)
+
+	return change
+}
+
+// planUsageLimits returns the usage limits of a license moving to a new plan:
+// the numeric features of the new type, overridden by the limits set on the
+// license itself, i.e. those that differ from the old type's
+func planUsageLimits(current map[string]any, oldType, newType *models.LicenseType) map[string]any {
+	limits := make(map[string]any)
+	for key, value := range newType.Features {
+		if _, ok := intValue(value); ok {
+			limits[key] = value
+		}
+	}
+
+	for key, value := range current {
+		if typeValue, ok := oldType.Features[key]; ok && reflect.DeepEqual(typeValue, value) {
+			continue
+		}
+		limits[key] = value
+	}
+
+	return limits
+}
+
+// remainingDays returns the number of started days left until expiry, capped
+// at the length of a full term
+func remainingDays(expiry, now time.Time, termDays int) int {
+	remaining := expiry.Sub(now)
+	if remaining <= 0 {
+		return 0
+	}
+
+	days := int(math.Ceil(remaining.Hours() / 24))
+	if termDays > 0 && days > termDays {
+		days = termDays
+	}
+	return days
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
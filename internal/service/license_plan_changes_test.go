@@ -0,0 +1,160 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+)
+
+func TestQuotePlanChange(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	basic := &models.LicenseType{Name: "basic", DurationDays: 30, Price: 30}
+	pro := &models.LicenseType{Name: "pro", DurationDays: 30, Price: 90}
+	team := &models.LicenseType{Name: "team", DurationDays: 30, Price: 90}
+	yearly := &models.LicenseType{Name: "yearly", DurationDays: 365, Price: 365}
+	perpetual := &models.LicenseType{Name: "perpetual", DurationDays: 0, Price: 500}
+
+	tests := []struct {
+		name        string
+		oldType     *models.LicenseType
+		newType     *models.LicenseType
+		expiry      time.Time
+		restartTerm bool
+		want        PlanChange
+	}{
+		{
+			name:    "upgrade keeping the term",
+			oldType: basic,
+			newType: pro,
+			expiry:  now.AddDate(0, 0, 10),
+			want:    PlanChange{Direction: "upgrade", RemainingDays: 10, ProratedCredit: 10, Charge: 30, AmountDue: 20},
+		},
+		{
+			name:    "downgrade keeping the term",
+			oldType: pro,
+			newType: basic,
+			expiry:  now.AddDate(0, 0, 15),
+			want:    PlanChange{Direction: "downgrade", RemainingDays: 15, ProratedCredit: 45, Charge: 15, AmountDue: -30},
+		},
+		{
+			name:    "lateral at the same price",
+			oldType: pro,
+			newType: team,
+			expiry:  now.AddDate(0, 0, 20),
+			want:    PlanChange{Direction: "lateral", RemainingDays: 20, ProratedCredit: 60, Charge: 60, AmountDue: 0},
+		},
+		{
+			name:        "restart charges a full term",
+			oldType:     basic,
+			newType:     yearly,
+			expiry:      now.AddDate(0, 0, 10),
+			restartTerm: true,
+			want:        PlanChange{Direction: "upgrade", RemainingDays: 10, ProratedCredit: 10, Charge: 365, AmountDue: 355},
+		},
+		{
+			name:    "partial day counts as started",
+			oldType: basic,
+			newType: pro,
+			expiry:  now.Add(36 * time.Hour),
+			want:    PlanChange{Direction: "upgrade", RemainingDays: 2, ProratedCredit: 2, Charge: 6, AmountDue: 4},
+		},
+		{
+			name:    "remaining days capped at a full term",
+			oldType: basic,
+			newType: pro,
+			expiry:  now.AddDate(0, 0, 45),
+			want:    PlanChange{Direction: "upgrade", RemainingDays: 30, ProratedCredit: 30, Charge: 90, AmountDue: 60},
+		},
+		{
+			name:    "expired license gets no credit",
+			oldType: basic,
+			newType: pro,
+			expiry:  now.AddDate(0, 0, -3),
+			want:    PlanChange{Direction: "upgrade", RemainingDays: 0, ProratedCredit: 0, Charge: 0, AmountDue: 0},
+		},
+		{
+			name:    "rounded to cents",
+			oldType: &models.LicenseType{DurationDays: 30, Price: 10},
+			newType: &models.LicenseType{DurationDays: 30, Price: 20},
+			expiry:  now.AddDate(0, 0, 7),
+			want:    PlanChange{Direction: "upgrade", RemainingDays: 7, ProratedCredit: 2.33, Charge: 4.67, AmountDue: 2.34},
+		},
+		{
+			name:    "old type without duration gives no credit",
+			oldType: perpetual,
+			newType: basic,
+			expiry:  now.AddDate(0, 0, 10),
+			want:    PlanChange{Direction: "downgrade", RemainingDays: 10, ProratedCredit: 0, Charge: 10, AmountDue: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := quotePlanChange(tt.oldType, tt.newType, tt.expiry, now, tt.restartTerm)
+			got.OldLicenseTypeID, got.NewLicenseTypeID = tt.want.OldLicenseTypeID, tt.want.NewLicenseTypeID
+			if *got != tt.want {
+				t.Errorf("quotePlanChange() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanUsageLimits(t *testing.T) {
+	oldType := &models.LicenseType{Features: map[string]any{
+		"max_seats":    float64(5),
+		"api_calls":    float64(1000),
+		"export":       true,
+		"storage_gb":   float64(10),
+		"support_tier": "basic",
+	}}
+	newType := &models.LicenseType{Features: map[string]any{
+		"max_seats":    float64(20),
+		"api_calls":    float64(10000),
+		"export":       true,
+		"support_tier": "priority",
+		"projects":     float64(50),
+	}}
+
+	tests := []struct {
+		name    string
+		current map[string]any
+		want    map[string]any
+	}{
+		{
+			name:    "limits from the old type follow the new type",
+			current: map[string]any{"max_seats": float64(5), "api_calls": float64(1000)},
+			want:    map[string]any{"max_seats": float64(20), "api_calls": float64(10000), "projects": float64(50)},
+		},
+		{
+			name:    "limits set on the license are kept",
+			current: map[string]any{"max_seats": float64(8), "api_calls": float64(1000)},
+			want:    map[string]any{"max_seats": float64(8), "api_calls": float64(10000), "projects": float64(50)},
+		},
+		{
+			name:    "limits unknown to the old type are kept",
+			current: map[string]any{"custom_quota": float64(3)},
+			want:    map[string]any{"max_seats": float64(20), "api_calls": float64(10000), "projects": float64(50), "custom_quota": float64(3)},
+		},
+		{
+			name:    "old type limits dropped by the new type are removed",
+			current: map[string]any{"storage_gb": float64(10)},
+			want:    map[string]any{"max_seats": float64(20), "api_calls": float64(10000), "projects": float64(50)},
+		},
+		{
+			name:    "no license limits",
+			current: nil,
+			want:    map[string]any{"max_seats": float64(20), "api_calls": float64(10000), "projects": float64(50)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planUsageLimits(tt.current, oldType, newType)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planUsageLimits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
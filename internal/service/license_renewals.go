@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+)
+
+// Renew extends the license expiry by the given number of days, or by the
+// license type duration when days is zero. Renewals of a license that has not
+// expired yet stack on its current expiry date; expired licenses are renewed
+// from today and re-activated.
+func (s *licenseService) Renew(ctx context.Context, id uuid.UUID, days int) (*models.License, error) {
+	if days < 0 {
+		return nil, fmt.Errorf("%w: renewal period cannot be negative", ErrInvalidInput)
+	}
+
+	// The license is locked while its term is read and extended, so
+	// concurrent renewals stack instead of overwriting each other
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		license, err := s.lockLicense(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if license.IsRevoked {
+			return ErrLicenseRevoked
+		}
+
+		if days == 0 {
+			licenseType, err := s.licenseTypeRepo.GetByID(ctx, license.LicenseTypeID)
+			if err != nil {
+				return err
+			}
+			days = licenseType.DurationDays
+		}
+		if days <= 0 {
+			return fmt.Errorf("%w: license type has no duration, renewal period is required", ErrInvalidInput)
+		}
+
+		now := time.Now()
+		oldExpiry := license.ExpiryDate
+		expired := now.After(oldExpiry)
+
+		base := oldExpiry
+		if expired {
+			base = now
+		}
+		license.ExpiryDate = base.AddDate(0, 0, days)
+
+		reactivated := expired && !license.IsActive
+		if expired {
+			license.IsActive = true
+		}
+
+		if err := s.repo.UpdateTerm(ctx, license.ID, license.ExpiryDate, license.IsActive); err != nil {
+			return err
+		}
+
+		// Record renewal activity
+		activity := &models.LicenseActivity{
+			LicenseID:    license.ID,
+			ActivityType: "renewal",
+			Description:  fmt.Sprintf("License renewed for %d days", days),
+			Metadata: map[string]interface{}{
+				"old_expiry_date": oldExpiry.Format(time.DateOnly),
+				"new_expiry_date": license.ExpiryDate.Format(time.DateOnly),
+				"days":            days,
+				"reactivated":     reactivated,
+			},
+		}
+		if err := s.RecordActivity(ctx, activity); err != nil {
+			return err
+		}
+
+		data := licenseEventData(license)
+		data["old_expiry_date"] = oldExpiry.Format(time.DateOnly)
+		data["days"] = days
+		return s.publish(ctx, events.LicenseRenewed, license, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
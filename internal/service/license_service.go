@@ -2,16 +2,20 @@ package service
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
+	"errors"
 	"fmt"
+	"net"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
 	"github.com/LywwKkA-aD/golicensemanager/internal/models"
 	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+	"github.com/LywwKkA-aD/golicensemanager/internal/requestmeta"
+	"github.com/LywwKkA-aD/golicensemanager/pkg/licensefile"
 )
 
 type LicenseFilters struct {
@@ -21,11 +25,45 @@ type LicenseFilters struct {
 	IsRevoked     *bool      `form:"is_revoked"`
 }
 
+type ActivityFilters struct {
+	ActivityType string `form:"activity_type"`
+	IPAddress    string `form:"ip_address"`
+}
+
+// ValidateOptions carries optional client-supplied context for license validation
+type ValidateOptions struct {
+	// ApplicationID selects the key format used to reject mistyped keys early
+	ApplicationID uuid.UUID
+	// Fingerprint identifies the machine; when set, the machine must be activated
+	Fingerprint string
+	// Version is the client's running application version, checked against
+	// the license's version constraint
+	Version string
+}
+
+// License statuses reported by validation
+const (
+	LicenseStatusActive    = "active"
+	LicenseStatusGrace     = "grace"
+	LicenseStatusExpired   = "expired"
+	LicenseStatusRevoked   = "revoked"
+	LicenseStatusSuspended = "suspended"
+	LicenseStatusInactive  = "inactive"
+
+	LicenseStatusVersionNotAllowed = "version_not_allowed"
+)
+
 type ValidationResult struct {
-	Valid     bool                   `json:"valid"`
-	Message   string                 `json:"message"`
-	ExpiresAt time.Time              `json:"expires_at"`
-	Features  map[string]interface{} `json:"features"`
+	Valid              bool                   `json:"valid"`
+	Status             string                 `json:"status"`
+	Message            string                 `json:"message"`
+	ExpiresAt          time.Time              `json:"expires_at"`
+	GraceDaysRemaining *int                   `json:"grace_days_remaining,omitempty"`
+	IsTrial            bool                   `json:"is_trial"`
+	TrialEndsAt        *time.Time             `json:"trial_ends_at,omitempty"`
+	AllowedVersions    string                 `json:"allowed_versions,omitempty"`
+	Features           map[string]interface{} `json:"features"`
+	FeatureSources     map[string]string      `json:"feature_sources"`
 }
 
 type LicenseService interface {
@@ -34,27 +72,95 @@ type LicenseService interface {
 	List(ctx context.Context, filters LicenseFilters) ([]models.License, error)
 	Update(ctx context.Context, license *models.License) (*models.License, error)
 	Revoke(ctx context.Context, id uuid.UUID, reason string) error
-	Validate(ctx context.Context, licenseKey string) (*ValidationResult, error)
+	Validate(ctx context.Context, licenseKey string, opts ValidateOptions) (*ValidationResult, error)
 	GetByKey(ctx context.Context, licenseKey string) (*models.License, error)
 	RecordActivity(ctx context.Context, activity *models.LicenseActivity) error
+	ListActivities(ctx context.Context, id uuid.UUID, filters ActivityFilters) ([]models.LicenseActivity, error)
 	CheckUsage(ctx context.Context, licenseKey string, usage map[string]interface{}) error
+	RecordUsage(ctx context.Context, req UsageRequest) (*UsageResult, error)
+	ResetUsage(ctx context.Context) (int, error)
+	NotifyExpiring(ctx context.Context, windows []int) (int, error)
+	ExpireLicenses(ctx context.Context) (int64, error)
+	UsageHistory(ctx context.Context, id uuid.UUID, metric string) ([]models.UsageHistory, error)
+	GetLicenseFile(ctx context.Context, id uuid.UUID) (string, error)
+	Activate(ctx context.Context, req ActivationRequest) (*models.Activation, error)
+	Deactivate(ctx context.Context, applicationID uuid.UUID, licenseKey, fingerprint string) error
+	ListActivations(ctx context.Context, id uuid.UUID) ([]models.Activation, error)
+	CheckoutLease(ctx context.Context, req LeaseRequest) (*models.LicenseLease, error)
+	HeartbeatLease(ctx context.Context, leaseID uuid.UUID) (*models.LicenseLease, error)
+	ReleaseLease(ctx context.Context, leaseID uuid.UUID) error
+	ListLeases(ctx context.Context, id uuid.UUID) ([]models.LicenseLease, error)
+	ExpireLeases(ctx context.Context) (int64, error)
+	Renew(ctx context.Context, id uuid.UUID, days int) (*models.License, error)
+	ConvertTrial(ctx context.Context, id, licenseTypeID uuid.UUID) (*models.License, error)
+	Suspend(ctx context.Context, id uuid.UUID, reason string, reinstateAt *time.Time) (*models.License, error)
+	Reinstate(ctx context.Context, id uuid.UUID) (*models.License, error)
+	ReinstateDue(ctx context.Context) (int, error)
+	ChangePlan(ctx context.Context, id uuid.UUID, req PlanChangeRequest) (*PlanChange, error)
+	UpdateFeatures(ctx context.Context, id uuid.UUID, ops []FeatureOperation) (*models.License, error)
+	Transfer(ctx context.Context, id uuid.UUID, req TransferRequest) (*models.License, error)
+	TransferClientLicenses(ctx context.Context, fromClientID uuid.UUID, req TransferRequest) ([]models.License, error)
+	BulkIssue(ctx context.Context, req BulkIssueRequest) (*BulkIssueResult, error)
+	AttachAddon(ctx context.Context, id uuid.UUID, req AddonRequest) (*models.LicenseAddon, error)
+	RenewAddon(ctx context.Context, id, addonID uuid.UUID, days int) (*models.LicenseAddon, error)
+	DetachAddon(ctx context.Context, id, addonID uuid.UUID) error
 }
 
 type licenseService struct {
-	repo            repository.LicenseRepository
-	licenseTypeRepo repository.LicenseTypeRepository
-	logger          *zap.SugaredLogger
+	repo             repository.LicenseRepository
+	appRepo          repository.ApplicationRepository
+	licenseTypeRepo  repository.LicenseTypeRepository
+	clientRepo       repository.ClientRepository
+	activationRepo   repository.ActivationRepository
+	leaseRepo        repository.LeaseRepository
+	usageRepo        repository.UsageRepository
+	noticeRepo       repository.ExpiryNoticeRepository
+	addonRepo        repository.LicenseAddonRepository
+	subscriptionRepo repository.SubscriptionRepository
+	tx               repository.Transactor
+	features         FeatureService
+	keys             KeyService
+	publisher        events.Publisher
+	logger           *zap.SugaredLogger
+
+	keyGenMu sync.RWMutex
+	keyGens  map[uuid.UUID]cachedKeyGenerator
 }
 
 func NewLicenseService(
 	repo repository.LicenseRepository,
+	appRepo repository.ApplicationRepository,
 	licenseTypeRepo repository.LicenseTypeRepository,
+	clientRepo repository.ClientRepository,
+	activationRepo repository.ActivationRepository,
+	leaseRepo repository.LeaseRepository,
+	usageRepo repository.UsageRepository,
+	noticeRepo repository.ExpiryNoticeRepository,
+	addonRepo repository.LicenseAddonRepository,
+	subscriptionRepo repository.SubscriptionRepository,
+	tx repository.Transactor,
+	features FeatureService,
+	keys KeyService,
+	publisher events.Publisher,
 	logger *zap.SugaredLogger,
 ) LicenseService {
 	return &licenseService{
-		repo:            repo,
-		licenseTypeRepo: licenseTypeRepo,
-		logger:          logger,
+		repo:             repo,
+		appRepo:          appRepo,
+		licenseTypeRepo:  licenseTypeRepo,
+		clientRepo:       clientRepo,
+		activationRepo:   activationRepo,
+		leaseRepo:        leaseRepo,
+		usageRepo:        usageRepo,
+		noticeRepo:       noticeRepo,
+		addonRepo:        addonRepo,
+		subscriptionRepo: subscriptionRepo,
+		tx:               tx,
+		features:         features,
+		keys:             keys,
+		publisher:        publisher,
+		logger:           logger,
+		keyGens:          make(map[uuid.UUID]cachedKeyGenerator),
 	}
 }
 
@@ -69,13 +175,20 @@ func (s *licenseService) Create(ctx context.Context, license *models.License) (*
 	if err != nil {
 		return nil, fmt.Errorf("failed to get license type: %w", err)
 	}
+	if licenseType.IsAddon {
+		return nil, fmt.Errorf("%w: add-on license types cannot be issued on their own", ErrInvalidInput)
+	}
 
 	// Set license dates
 	license.StartDate = time.Now()
 	license.ExpiryDate = license.StartDate.AddDate(0, 0, licenseType.DurationDays)
 
-	// Generate license key
-	licenseKey, err := generateLicenseKey(license)
+	// Generate license key in the application's format
+	keyGen, err := s.keyGenerator(ctx, license.ApplicationID)
+	if err != nil {
+		return nil, err
+	}
+	licenseKey, err := keyGen.Generate()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate license key: %w", err)
 	}
@@ -85,11 +198,46 @@ func (s *licenseService) Create(ctx context.Context, license *models.License) (*
 	if license.UsageLimits == nil {
 		license.UsageLimits = licenseType.Features
 	}
+	if err := s.features.ValidateValues(ctx, license.ApplicationID, "usage_limits", license.UsageLimits); err != nil {
+		return nil, err
+	}
 
 	// Initialize current usage
 	license.CurrentUsage = make(map[string]interface{})
 
-	return s.repo.Create(ctx, license)
+	// The client name goes into the license file
+	client, err := s.clientRepo.GetByID(ctx, license.ApplicationID, license.ClientID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, fmt.Errorf("%w: client not found", ErrInvalidInput)
+		}
+		return nil, err
+	}
+
+	// Store the license together with its event. The license file is signed
+	// in the same transaction, so a signing failure leaves nothing behind.
+	var createdLicense *models.License
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		createdLicense, err = s.repo.Create(ctx, license)
+		if err != nil {
+			return err
+		}
+		createdLicense.Client = *client
+
+		// Issue signed license file for offline verification
+		licenseFile, err := s.signLicense(ctx, createdLicense, licenseType)
+		if err != nil {
+			return fmt.Errorf("failed to issue license file: %w", err)
+		}
+		createdLicense.LicenseFile = licenseFile
+
+		return s.publish(ctx, events.LicenseCreated, createdLicense, licenseEventData(createdLicense))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return createdLicense, nil
 }
 
 func (s *licenseService) GetByID(ctx context.Context, id uuid.UUID) (*models.License, error) {
@@ -103,6 +251,19 @@ func (s *licenseService) GetByID(ctx context.Context, id uuid.UUID) (*models.Lic
 	return license, nil
 }
 
+// lockLicense locks the license for the rest of the transaction. Its
+// associations are not loaded.
+func (s *licenseService) lockLicense(ctx context.Context, id uuid.UUID) (*models.License, error) {
+	license, err := s.repo.GetForUpdate(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return license, nil
+}
+
 func (s *licenseService) List(ctx context.Context, filters LicenseFilters) ([]models.License, error) {
 	return s.repo.List(ctx, repository.LicenseFilters{
 		ApplicationID: filters.ApplicationID,
@@ -126,11 +287,28 @@ func (s *licenseService) Update(ctx context.Context, license *models.License) (*
 		return nil, err
 	}
 
+	// Plan changes recompute dates and limits, so they go through ChangePlan
+	if license.LicenseTypeID != existing.LicenseTypeID {
+		return nil, fmt.Errorf("%w: license type cannot be changed on update, use change-plan", ErrInvalidInput)
+	}
+
+	if license.UsageLimits == nil {
+		license.UsageLimits = existing.UsageLimits
+	}
+	if err := s.features.ValidateValues(ctx, license.ApplicationID, "usage_limits", license.UsageLimits); err != nil {
+		return nil, err
+	}
+
 	// Preserve certain fields
 	license.LicenseKey = existing.LicenseKey
 	license.StartDate = existing.StartDate
 	license.ExpiryDate = existing.ExpiryDate
 	license.CurrentUsage = existing.CurrentUsage
+	license.FeatureOverrides = existing.FeatureOverrides
+	license.IsSuspended = existing.IsSuspended
+	license.SuspensionReason = existing.SuspensionReason
+	license.SuspendedAt = existing.SuspendedAt
+	license.ReinstateAt = existing.ReinstateAt
 
 	return s.repo.Update(ctx, license)
 }
@@ -148,71 +326,99 @@ func (s *licenseService) Revoke(ctx context.Context, id uuid.UUID, reason string
 	license.RevocationReason = &reason
 	license.IsActive = false
 
-	_, err = s.repo.Update(ctx, license)
-	if err != nil {
-		return err
-	}
+	// The revocation, its activity and its event are stored together
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := s.repo.Update(ctx, license); err != nil {
+			return err
+		}
 
-	// Record revocation activity
-	activity := &models.LicenseActivity{
-		LicenseID:    id,
-		ActivityType: "revocation",
-		Description:  fmt.Sprintf("License revoked: %s", reason),
-		Metadata: map[string]interface{}{
-			"reason": reason,
-		},
-	}
+		// Record revocation activity
+		activity := &models.LicenseActivity{
+			LicenseID:    id,
+			ActivityType: "revocation",
+			Description:  fmt.Sprintf("License revoked: %s", reason),
+			Metadata: map[string]interface{}{
+				"reason": reason,
+			},
+		}
+		if err := s.RecordActivity(ctx, activity); err != nil {
+			return err
+		}
 
-	return s.RecordActivity(ctx, activity)
+		data := licenseEventData(license)
+		data["reason"] = reason
+		return s.publish(ctx, events.LicenseRevoked, license, data)
+	})
 }
 
-func (s *licenseService) Validate(ctx context.Context, licenseKey string) (*ValidationResult, error) {
-	license, err := s.repo.GetByKey(ctx, licenseKey)
+func (s *licenseService) Validate(ctx context.Context, licenseKey string, opts ValidateOptions) (*ValidationResult, error) {
+	license, err := s.getByLicenseKey(ctx, opts.ApplicationID, licenseKey)
 	if err != nil {
-		if err == repository.ErrNotFound {
-			return nil, ErrLicenseInvalid
-		}
 		return nil, err
 	}
 
 	result := &ValidationResult{
 		Valid:     true,
 		ExpiresAt: license.ExpiryDate,
+		IsTrial:   license.LicenseType.IsTrial,
 		Features:  make(map[string]interface{}),
 	}
+	if result.IsTrial {
+		result.TrialEndsAt = &license.ExpiryDate
+	}
 
-	// Check if license is revoked
-	if license.IsRevoked {
+	status, message, err := checkLicenseStatus(license)
+	result.Status = status
+	result.Message = message
+	if err != nil {
 		result.Valid = false
-		result.Message = "License has been revoked"
-		return result, ErrLicenseRevoked
+		return result, err
+	}
+	if status == LicenseStatusGrace {
+		days := graceDaysRemaining(license, time.Now())
+		result.GraceDaysRemaining = &days
 	}
 
-	// Check if license is expired
-	if time.Now().After(license.ExpiryDate) {
-		result.Valid = false
-		result.Message = "License has expired"
-		return result, ErrLicenseExpired
+	// Check that the client runs an allowed application version
+	result.AllowedVersions = versionConstraint(license)
+	if err := checkClientVersion(license, opts.Version); err != nil {
+		if errors.Is(err, ErrVersionNotAllowed) {
+			result.Valid = false
+			result.Status = LicenseStatusVersionNotAllowed
+			result.Message = "Application version is not allowed by this license"
+			return result, err
+		}
+		return nil, err
 	}
 
-	// Check if license is active
-	if !license.IsActive {
-		result.Valid = false
-		result.Message = "License is not active"
-		return result, ErrLicenseInvalid
+	// Check that the machine is activated when a fingerprint is supplied
+	if opts.Fingerprint != "" {
+		activation, err := s.activationRepo.GetByFingerprint(ctx, license.ID, opts.Fingerprint)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				result.Valid = false
+				result.Message = "Machine is not activated for this license"
+				return result, ErrMachineNotActivated
+			}
+			return nil, err
+		}
+		if err := s.activationRepo.Touch(ctx, activation.ID, ""); err != nil {
+			s.logger.Warnf("Failed to update activation last seen time: %v", err)
+		}
 	}
 
-	// Get license type to include features
+	// Get license type to include features, merged with the license overrides
+	// and active add-ons
 	licenseType, err := s.licenseTypeRepo.GetByID(ctx, license.LicenseTypeID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get license type: %w", err)
 	}
-	result.Features = licenseType.Features
+	result.Features, result.FeatureSources = licenseEntitlements(license, licenseType, time.Now())
 
 	// Update last check time
 	now := time.Now()
 	license.LastCheck = &now
-	if _, err := s.repo.Update(ctx, license); err != nil {
+	if err := s.repo.UpdateLastCheck(ctx, license.ID, now); err != nil {
 		s.logger.Warnf("Failed to update license last check time: %v", err)
 	}
 
@@ -222,8 +428,29 @@ func (s *licenseService) Validate(ctx context.Context, licenseKey string) (*Vali
 		ActivityType: "validation",
 		Description:  "License validated successfully",
 	}
-	if err := s.RecordActivity(ctx, activity); err != nil {
-		s.logger.Warnf("Failed to record license activity: %v", err)
+	if opts.Fingerprint != "" {
+		activity.Metadata = map[string]interface{}{
+			"fingerprint": opts.Fingerprint,
+		}
+	}
+	data := licenseEventData(license)
+	data["status"] = result.Status
+	if opts.Fingerprint != "" {
+		data["fingerprint"] = opts.Fingerprint
+	}
+	if opts.Version != "" {
+		data["version"] = opts.Version
+	}
+
+	// A failure to record the validation does not fail the validation
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.RecordActivity(ctx, activity); err != nil {
+			return err
+		}
+		return s.publish(ctx, events.LicenseValidated, license, data)
+	})
+	if err != nil {
+		s.logger.Warnf("Failed to record license validation: %v", err)
 	}
 
 	return result, nil
@@ -240,13 +467,51 @@ func (s *licenseService) GetByKey(ctx context.Context, licenseKey string) (*mode
 	return license, nil
 }
 
+// RecordActivity stores a license activity. The request metadata carried by
+// ctx fills in whatever the caller did not set itself.
 func (s *licenseService) RecordActivity(ctx context.Context, activity *models.LicenseActivity) error {
 	if activity.CreatedAt.IsZero() {
 		activity.CreatedAt = time.Now()
 	}
+	if md, ok := requestmeta.FromContext(ctx); ok {
+		if activity.IPAddress == "" {
+			activity.IPAddress = md.IPAddress
+		}
+		if activity.UserAgent == "" {
+			activity.UserAgent = md.UserAgent
+		}
+		if activity.RequestID == "" {
+			activity.RequestID = md.RequestID
+		}
+		if activity.Principal == "" {
+			activity.Principal = md.Principal
+		}
+	}
 	return s.repo.CreateActivity(ctx, activity)
 }
 
+func (s *licenseService) ListActivities(ctx context.Context, id uuid.UUID, filters ActivityFilters) ([]models.LicenseActivity, error) {
+	if filters.IPAddress != "" {
+		ip := net.ParseIP(filters.IPAddress)
+		if ip == nil {
+			return nil, fmt.Errorf("%w: invalid IP address", ErrInvalidInput)
+		}
+		// Match the canonical form stored from the request
+		filters.IPAddress = ip.String()
+	}
+
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetActivities(ctx, id, repository.ActivityFilters{
+		ActivityType: filters.ActivityType,
+		IPAddress:    filters.IPAddress,
+	})
+}
+
+// CheckUsage reports absolute usage values, rejecting values above the
+// license's limits. Counters are overwritten atomically; use RecordUsage to
+// count increments.
 func (s *licenseService) CheckUsage(ctx context.Context, licenseKey string, usage map[string]interface{}) error {
 	license, err := s.GetByKey(ctx, licenseKey)
 	if err != nil {
@@ -254,11 +519,12 @@ func (s *licenseService) CheckUsage(ctx context.Context, licenseKey string, usag
 	}
 
 	// Validate the license first
-	if _, err := s.Validate(ctx, licenseKey); err != nil {
+	if _, err := s.Validate(ctx, licenseKey, ValidateOptions{}); err != nil {
 		return err
 	}
 
 	// Check each usage metric against limits
+	values := make(map[string]float64, len(usage))
 	for metric, value := range usage {
 		limit, exists := license.UsageLimits[metric]
 		if !exists {
@@ -280,13 +546,76 @@ func (s *licenseService) CheckUsage(ctx context.Context, licenseKey string, usag
 			return fmt.Errorf("%w: %s exceeds allowed limit", ErrLicenseUsageLimitExceeded, metric)
 		}
 
-		// Update current usage
-		license.CurrentUsage[metric] = currentValue
+		values[metric] = currentValue
+	}
+
+	if len(values) == 0 {
+		return nil
 	}
 
 	// Update license with new usage data
-	_, err = s.repo.Update(ctx, license)
-	return err
+	if err := s.repo.SetUsage(ctx, license.ID, values); err != nil {
+		if err == repository.ErrNotFound {
+			return ErrLicenseInvalid
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *licenseService) GetLicenseFile(ctx context.Context, id uuid.UUID) (string, error) {
+	license, err := s.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	return s.signLicense(ctx, license, &license.LicenseType)
+}
+
+func (s *licenseService) signLicense(ctx context.Context, license *models.License, licenseType *models.LicenseType) (string, error) {
+	kid, privateKey, err := s.keys.SigningKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	features, _ := mergeFeatures(licenseType.Features, license.FeatureOverrides)
+	constraint := license.VersionConstraint
+	if constraint == "" {
+		constraint = licenseType.VersionConstraint
+	}
+
+	doc := &licensefile.Document{
+		KeyID:             kid,
+		LicenseID:         license.ID,
+		LicenseKey:        license.LicenseKey,
+		ApplicationID:     license.ApplicationID,
+		ClientID:          license.ClientID,
+		ClientName:        license.Client.Name,
+		LicenseTypeID:     license.LicenseTypeID,
+		LicenseTypeName:   licenseType.Name,
+		Features:          features,
+		UsageLimits:       license.UsageLimits,
+		VersionConstraint: constraint,
+		StartDate:         dateOnly(license.StartDate),
+		ExpiryDate:        dateOnly(license.ExpiryDate),
+		IssuedAt:          time.Now().UTC(),
+	}
+
+	// Expired add-ons are left out, running ones carry their own dates
+	for _, addon := range license.Addons {
+		if time.Now().After(addon.ExpiryDate) {
+			continue
+		}
+		doc.Addons = append(doc.Addons, licensefile.Addon{
+			LicenseTypeID:   addon.LicenseTypeID,
+			LicenseTypeName: addon.LicenseType.Name,
+			Features:        addon.LicenseType.Features,
+			StartDate:       dateOnly(addon.StartDate),
+			ExpiryDate:      dateOnly(addon.ExpiryDate),
+		})
+	}
+
+	return licensefile.Sign(doc, privateKey)
 }
 
 // Helper functions
@@ -301,22 +630,105 @@ func validateLicense(license *models.License) error {
 	if license.ClientID == uuid.Nil {
 		return fmt.Errorf("%w: client ID is required", ErrInvalidInput)
 	}
+	if license.MaxActivations != nil && *license.MaxActivations < 0 {
+		return fmt.Errorf("%w: max activations cannot be negative", ErrInvalidInput)
+	}
+	if license.MaxLeases != nil && *license.MaxLeases < 0 {
+		return fmt.Errorf("%w: max leases cannot be negative", ErrInvalidInput)
+	}
+	if err := validateUsageResetPeriods(license.UsageResetPeriods); err != nil {
+		return err
+	}
+	if err := validateVersionConstraint(license.VersionConstraint); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	return nil
+}
+
+// checkLicenseStatus reports whether the license can currently be used,
+// returning its status and a human readable message alongside the error.
+// Licenses past their expiry date but within the grace period remain usable.
+func checkLicenseStatus(license *models.License) (string, string, error) {
+	// Check if license is revoked
+	if license.IsRevoked {
+		return LicenseStatusRevoked, "License has been revoked", ErrLicenseRevoked
+	}
+
+	// Check if license is suspended; lapsed suspensions are awaiting reinstatement
+	now := time.Now()
+	if license.IsSuspended && (license.ReinstateAt == nil || now.Before(*license.ReinstateAt)) {
+		return LicenseStatusSuspended, "License is suspended", ErrLicenseSuspended
+	}
+
+	// Check if license is expired
+	inGracePeriod := false
+	if now.After(license.ExpiryDate) {
+		if !now.Before(graceEndDate(license)) {
+			return LicenseStatusExpired, "License has expired", ErrLicenseExpired
+		}
+		inGracePeriod = true
+	}
+
+	// Check if license is active
+	if !license.IsActive {
+		return LicenseStatusInactive, "License is not active", ErrLicenseInvalid
+	}
+
+	if inGracePeriod {
+		return LicenseStatusGrace, "License has expired and is in its grace period", nil
+	}
+
+	return LicenseStatusActive, "", nil
+}
+
+// publish adds a license event to the outbox. Call it inside the transaction
+// that changes the license, so the event is stored if and only if the change is.
+func (s *licenseService) publish(ctx context.Context, eventType string, license *models.License, data map[string]any) error {
+	event := events.New(eventType, license.ApplicationID, license.ID, data)
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", eventType, err)
+	}
 	return nil
 }
 
-func generateLicenseKey(license *models.License) (string, error) {
-	// Create a unique string combining multiple fields
-	unique := fmt.Sprintf("%s-%s-%s-%d",
-		license.ApplicationID.String(),
-		license.ClientID.String(),
-		license.LicenseTypeID.String(),
-		time.Now().UnixNano(),
-	)
-
-	// Create SHA-256 hash
-	hash := sha256.New()
-	hash.Write([]byte(unique))
-
-	// Return first 32 characters of hex-encoded hash
-	return hex.EncodeToString(hash.Sum(nil))[:32], nil
+// dateOnly returns midnight UTC of the date of t, as read back from a date
+// column, so a license file signed before the license is stored carries the
+// same dates as one signed later
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// licenseEventData returns the license details included in every event
+func licenseEventData(license *models.License) map[string]any {
+	return map[string]any{
+		"client_id":       license.ClientID.String(),
+		"license_type_id": license.LicenseTypeID.String(),
+		"license_key":     license.LicenseKey,
+		"expiry_date":     license.ExpiryDate.Format(time.DateOnly),
+	}
+}
+
+// gracePeriodDays returns the grace period of the license type, falling back
+// to the application default. Trials end without a grace period.
+func gracePeriodDays(license *models.License) int {
+	if license.LicenseType.IsTrial {
+		return 0
+	}
+	if license.LicenseType.GracePeriodDays != nil {
+		return *license.LicenseType.GracePeriodDays
+	}
+	return license.Application.GracePeriodDays
+}
+
+func graceEndDate(license *models.License) time.Time {
+	return license.ExpiryDate.AddDate(0, 0, gracePeriodDays(license))
+}
+
+// graceDaysRemaining returns the number of started days left in the grace period
+func graceDaysRemaining(license *models.License, now time.Time) int {
+	remaining := graceEndDate(license).Sub(now)
+	if remaining <= 0 {
+		return 0
+	}
+	return int((remaining + 24*time.Hour - 1) / (24 * time.Hour))
 }
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+)
+
+func TestGracePeriodDays(t *testing.T) {
+	days := func(n int) *int { return &n }
+
+	tests := []struct {
+		name        string
+		typeDays    *int
+		isTrial     bool
+		defaultDays int
+		want        int
+	}{
+		{name: "application default", typeDays: nil, defaultDays: 7, want: 7},
+		{name: "license type overrides the default", typeDays: days(14), defaultDays: 7, want: 14},
+		{name: "license type disables the grace period", typeDays: days(0), defaultDays: 7, want: 0},
+		{name: "no grace period anywhere", typeDays: nil, defaultDays: 0, want: 0},
+		{name: "trials end without a grace period", typeDays: days(14), isTrial: true, defaultDays: 7, want: 0},
+		{name: "trials ignore the default", typeDays: nil, isTrial: true, defaultDays: 7, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			license := &models.License{
+				LicenseType: models.LicenseType{GracePeriodDays: tt.typeDays, IsTrial: tt.isTrial},
+				Application: models.Application{GracePeriodDays: tt.defaultDays},
+			}
+			if got := gracePeriodDays(license); got != tt.want {
+				t.Errorf("gracePeriodDays() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
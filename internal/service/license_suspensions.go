@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+)
+
+// Suspend temporarily blocks the license. Unlike revocation it can be undone
+// with Reinstate, or automatically once reinstateAt has passed.
+func (s *licenseService) Suspend(ctx context.Context, id uuid.UUID, reason string, reinstateAt *time.Time) (*models.License, error) {
+	if reason == "" {
+		return nil, fmt.Errorf("%w: suspension reason is required", ErrInvalidInput)
+	}
+
+	now := time.Now()
+	if reinstateAt != nil && !reinstateAt.After(now) {
+		return nil, fmt.Errorf("%w: reinstate date must be in the future", ErrInvalidInput)
+	}
+
+	license, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if license.IsRevoked {
+		return nil, ErrLicenseRevoked
+	}
+
+	license.IsSuspended = true
+	license.SuspensionReason = &reason
+	license.SuspendedAt = &now
+	license.ReinstateAt = reinstateAt
+
+	// The suspension, its activity and its event are stored together
+	var updatedLicense *models.License
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		updatedLicense, err = s.repo.Update(ctx, license)
+		if err != nil {
+			return err
+		}
+
+		// Record suspension activity
+		metadata := map[string]interface{}{
+			"reason": reason,
+		}
+		if reinstateAt != nil {
+			metadata["reinstate_at"] = reinstateAt.Format(time.RFC3339)
+		}
+		activity := &models.LicenseActivity{
+			LicenseID:    license.ID,
+			ActivityType: "suspension",
+			Description:  fmt.Sprintf("License suspended: %s", reason),
+			Metadata:     metadata,
+		}
+		if err := s.RecordActivity(ctx, activity); err != nil {
+			return err
+		}
+
+		data := licenseEventData(license)
+		data["reason"] = reason
+		if reinstateAt != nil {
+			data["reinstate_at"] = reinstateAt.Format(time.RFC3339)
+		}
+		return s.publish(ctx, events.LicenseSuspended, license, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedLicense, nil
+}
+
+func (s *licenseService) Reinstate(ctx context.Context, id uuid.UUID) (*models.License, error) {
+	license, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !license.IsSuspended {
+		return nil, ErrLicenseNotSuspended
+	}
+
+	if err := s.reinstate(ctx, license, false); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// ReinstateDue reinstates suspended licenses whose reinstate date has passed
+// and returns how many were reinstated
+func (s *licenseService) ReinstateDue(ctx context.Context) (int, error) {
+	licenses, err := s.repo.ListDueReinstatements(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	reinstated := 0
+	for i := range licenses {
+		if err := s.reinstate(ctx, &licenses[i], true); err != nil {
+			if err == ErrLicenseNotSuspended {
+				continue
+			}
+			return reinstated, err
+		}
+		reinstated++
+	}
+
+	return reinstated, nil
+}
+
+func (s *licenseService) reinstate(ctx context.Context, license *models.License, automatic bool) error {
+	// The reinstatement, its activity and its event are stored together
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		ok, err := s.repo.Reinstate(ctx, license.ID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrLicenseNotSuspended
+		}
+
+		// Record reinstatement activity
+		metadata := map[string]interface{}{
+			"automatic": automatic,
+		}
+		if license.SuspensionReason != nil {
+			metadata["suspension_reason"] = *license.SuspensionReason
+		}
+		activity := &models.LicenseActivity{
+			LicenseID:    license.ID,
+			ActivityType: "reinstatement",
+			Description:  "License reinstated",
+			Metadata:     metadata,
+		}
+		if err := s.RecordActivity(ctx, activity); err != nil {
+			return err
+		}
+
+		data := licenseEventData(license)
+		data["automatic"] = automatic
+		return s.publish(ctx, events.LicenseReinstated, license, data)
+	})
+}
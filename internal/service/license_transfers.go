@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+type TransferRequest struct {
+	ApplicationID    uuid.UUID `json:"-"`
+	ToClientID       uuid.UUID `json:"to_client_id" binding:"required"`
+	ResetActivations bool      `json:"reset_activations"`
+}
+
+// Transfer moves a license to another client of the same application
+func (s *licenseService) Transfer(ctx context.Context, id uuid.UUID, req TransferRequest) (*models.License, error) {
+	license, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if license.ApplicationID != req.ApplicationID {
+		return nil, ErrNotFound
+	}
+	if license.ClientID == req.ToClientID {
+		return nil, fmt.Errorf("%w: license already belongs to this client", ErrInvalidInput)
+	}
+
+	if err := s.checkTransferTarget(ctx, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.transfer(ctx, []models.License{*license}, req); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// TransferClientLicenses moves all licenses of a client to another client of
+// the same application
+func (s *licenseService) TransferClientLicenses(ctx context.Context, fromClientID uuid.UUID, req TransferRequest) ([]models.License, error) {
+	if fromClientID == req.ToClientID {
+		return nil, fmt.Errorf("%w: source and target client are the same", ErrInvalidInput)
+	}
+
+	if _, err := s.clientRepo.GetByID(ctx, req.ApplicationID, fromClientID); err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.checkTransferTarget(ctx, req); err != nil {
+		return nil, err
+	}
+
+	licenses, err := s.repo.List(ctx, repository.LicenseFilters{
+		ApplicationID: req.ApplicationID,
+		ClientID:      &fromClientID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.transfer(ctx, licenses, req); err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(ctx, repository.LicenseFilters{
+		ApplicationID: req.ApplicationID,
+		ClientID:      &req.ToClientID,
+	})
+}
+
+func (s *licenseService) checkTransferTarget(ctx context.Context, req TransferRequest) error {
+	client, err := s.clientRepo.GetByID(ctx, req.ApplicationID, req.ToClientID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return fmt.Errorf("%w: target client not found", ErrInvalidInput)
+		}
+		return err
+	}
+	if !client.IsActive {
+		return fmt.Errorf("%w: target client is not active", ErrInvalidInput)
+	}
+	return nil
+}
+
+func (s *licenseService) transfer(ctx context.Context, licenses []models.License, req TransferRequest) error {
+	ids := make([]uuid.UUID, len(licenses))
+	for i, license := range licenses {
+		ids[i] = license.ID
+	}
+
+	// The transfer, its activities and its events are stored together
+	return s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Transfer(ctx, ids, req.ToClientID, req.ResetActivations); err != nil {
+			return err
+		}
+
+		for _, license := range licenses {
+			fromClientID := license.ClientID
+
+			// Record transfer activity
+			activity := &models.LicenseActivity{
+				LicenseID:    license.ID,
+				ActivityType: "transfer",
+				Description:  fmt.Sprintf("License transferred from client %s to client %s", fromClientID, req.ToClientID),
+				Metadata: map[string]interface{}{
+					"from_client_id":    fromClientID.String(),
+					"to_client_id":      req.ToClientID.String(),
+					"reset_activations": req.ResetActivations,
+				},
+			}
+			if err := s.RecordActivity(ctx, activity); err != nil {
+				return err
+			}
+
+			license.ClientID = req.ToClientID
+			data := licenseEventData(&license)
+			data["from_client_id"] = fromClientID.String()
+			data["reset_activations"] = req.ResetActivations
+			if err := s.publish(ctx, events.LicenseTransferred, &license, data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+// ConvertTrial moves a trial license onto a paid license type. The license
+// keeps its ID, key and activations; its term restarts with the paid type's
+// duration and its usage limits are taken from the paid type.
+func (s *licenseService) ConvertTrial(ctx context.Context, id, licenseTypeID uuid.UUID) (*models.License, error) {
+	// The license is locked while it is converted, so a concurrent renewal or
+	// plan change cannot overwrite the conversion or be overwritten by it
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		license, err := s.lockLicense(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if license.IsRevoked {
+			return ErrLicenseRevoked
+		}
+
+		trialType, err := s.licenseTypeRepo.GetByID(ctx, license.LicenseTypeID)
+		if err != nil {
+			return err
+		}
+		if !trialType.IsTrial {
+			return ErrLicenseNotTrial
+		}
+
+		paidType, err := s.licenseTypeRepo.GetByID(ctx, licenseTypeID)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return fmt.Errorf("%w: license type not found", ErrInvalidInput)
+			}
+			return err
+		}
+		if paidType.ApplicationID != license.ApplicationID {
+			return fmt.Errorf("%w: license type belongs to another application", ErrInvalidInput)
+		}
+		if paidType.IsTrial {
+			return fmt.Errorf("%w: cannot convert to another trial license type", ErrInvalidInput)
+		}
+		if paidType.IsAddon {
+			return fmt.Errorf("%w: cannot convert to an add-on license type", ErrInvalidInput)
+		}
+		if !paidType.IsActive {
+			return fmt.Errorf("%w: license type is not active", ErrInvalidInput)
+		}
+
+		trialEndsAt := license.ExpiryDate
+
+		now := time.Now()
+		license.LicenseTypeID = paidType.ID
+		license.StartDate = now
+		license.ExpiryDate = now.AddDate(0, 0, paidType.DurationDays)
+		license.UsageLimits = paidType.Features
+		license.IsActive = true
+
+		if err := s.repo.UpdatePlan(ctx, license); err != nil {
+			return err
+		}
+
+		// Record conversion activity
+		activity := &models.LicenseActivity{
+			LicenseID:    license.ID,
+			ActivityType: "trial_conversion",
+			Description:  fmt.Sprintf("Trial converted to %s", paidType.Name),
+			Metadata: map[string]interface{}{
+				"trial_license_type_id": trialType.ID.String(),
+				"license_type_id":       paidType.ID.String(),
+				"trial_ends_at":         trialEndsAt.Format(time.DateOnly),
+				"new_expiry_date":       license.ExpiryDate.Format(time.DateOnly),
+			},
+		}
+		if err := s.RecordActivity(ctx, activity); err != nil {
+			return err
+		}
+
+		data := licenseEventData(license)
+		data["trial_license_type_id"] = trialType.ID.String()
+		return s.publish(ctx, events.LicenseConverted, license, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+type LicenseTypeService interface {
+	Create(ctx context.Context, licenseType *models.LicenseType) (*models.LicenseType, error)
+	GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.LicenseType, error)
+	List(ctx context.Context, applicationID uuid.UUID) ([]models.LicenseType, error)
+	Update(ctx context.Context, licenseType *models.LicenseType) (*models.LicenseType, error)
+	Delete(ctx context.Context, applicationID, id uuid.UUID) error
+}
+
+type licenseTypeService struct {
+	repo     repository.LicenseTypeRepository
+	features FeatureService
+	logger   *zap.SugaredLogger
+}
+
+func NewLicenseTypeService(
+	repo repository.LicenseTypeRepository,
+	features FeatureService,
+	logger *zap.SugaredLogger,
+) LicenseTypeService {
+	return &licenseTypeService{
+		repo:     repo,
+		features: features,
+		logger:   logger,
+	}
+}
+
+func (s *licenseTypeService) Create(ctx context.Context, licenseType *models.LicenseType) (*models.LicenseType, error) {
+	// Validate input
+	if err := validateLicenseType(licenseType); err != nil {
+		return nil, err
+	}
+
+	// Fill in catalog defaults, then check the features against the catalog
+	features, err := s.features.ApplyDefaults(ctx, licenseType.ApplicationID, licenseType.Features)
+	if err != nil {
+		return nil, err
+	}
+	licenseType.Features = features
+
+	if err := s.features.ValidateValues(ctx, licenseType.ApplicationID, "features", licenseType.Features); err != nil {
+		return nil, err
+	}
+
+	licenseType.IsActive = true
+
+	return s.repo.Create(ctx, licenseType)
+}
+
+func (s *licenseTypeService) GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.LicenseType, error) {
+	licenseType, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if licenseType.ApplicationID != applicationID {
+		return nil, ErrNotFound
+	}
+	return licenseType, nil
+}
+
+func (s *licenseTypeService) List(ctx context.Context, applicationID uuid.UUID) ([]models.LicenseType, error) {
+	return s.repo.List(ctx, applicationID)
+}
+
+func (s *licenseTypeService) Update(ctx context.Context, licenseType *models.LicenseType) (*models.LicenseType, error) {
+	// Validate input
+	if err := validateLicenseType(licenseType); err != nil {
+		return nil, err
+	}
+
+	// Check existence
+	existing, err := s.GetByID(ctx, licenseType.ApplicationID, licenseType.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Licenses and add-ons keep referencing the type, so its role is fixed
+	if licenseType.IsAddon != existing.IsAddon {
+		return nil, &ValidationError{Fields: []FieldError{{Field: "is_addon", Message: "cannot be changed"}}}
+	}
+
+	if licenseType.Features == nil {
+		licenseType.Features = existing.Features
+	}
+	if err := s.features.ValidateValues(ctx, licenseType.ApplicationID, "features", licenseType.Features); err != nil {
+		return nil, err
+	}
+
+	// Preserve certain fields
+	licenseType.CreatedAt = existing.CreatedAt
+
+	return s.repo.Update(ctx, licenseType)
+}
+
+func (s *licenseTypeService) Delete(ctx context.Context, applicationID, id uuid.UUID) error {
+	licenseType, err := s.GetByID(ctx, applicationID, id)
+	if err != nil {
+		return err
+	}
+
+	// Soft delete, as issued licenses keep referencing their type
+	licenseType.IsActive = false
+	_, err = s.repo.Update(ctx, licenseType)
+	return err
+}
+
+// Helper functions
+
+func validateLicenseType(licenseType *models.LicenseType) error {
+	if licenseType.ApplicationID == uuid.Nil {
+		return fmt.Errorf("%w: application ID is required", ErrInvalidInput)
+	}
+
+	var fieldErrs []FieldError
+	if licenseType.Name == "" {
+		fieldErrs = append(fieldErrs, FieldError{Field: "name", Message: "is required"})
+	}
+	if licenseType.DurationDays <= 0 {
+		fieldErrs = append(fieldErrs, FieldError{Field: "duration_days", Message: "must be positive"})
+	}
+	if licenseType.Price < 0 {
+		fieldErrs = append(fieldErrs, FieldError{Field: "price", Message: "cannot be negative"})
+	}
+	if licenseType.IsAddon && licenseType.IsTrial {
+		fieldErrs = append(fieldErrs, FieldError{Field: "is_addon", Message: "cannot be combined with is_trial"})
+	}
+	if licenseType.GracePeriodDays != nil && *licenseType.GracePeriodDays < 0 {
+		fieldErrs = append(fieldErrs, FieldError{Field: "grace_period_days", Message: "cannot be negative"})
+	}
+	if err := validateVersionConstraint(licenseType.VersionConstraint); err != nil {
+		fieldErrs = append(fieldErrs, FieldError{Field: "version_constraint", Message: err.Error()})
+	}
+
+	if len(fieldErrs) > 0 {
+		return &ValidationError{Fields: fieldErrs}
+	}
+	return nil
+}
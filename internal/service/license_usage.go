@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+type UsageRequest struct {
+	ApplicationID uuid.UUID `json:"-"`
+	LicenseKey    string    `json:"license_key" binding:"required"`
+	Metric        string    `json:"metric" binding:"required"`
+	Delta         float64   `json:"delta" binding:"required"`
+}
+
+type UsageResult struct {
+	Metric    string     `json:"metric"`
+	Used      float64    `json:"used"`
+	Limit     *float64   `json:"limit,omitempty"`
+	Remaining *float64   `json:"remaining,omitempty"`
+	ResetsAt  *time.Time `json:"resets_at,omitempty"`
+}
+
+// RecordUsage atomically increments (or, with a negative delta, decrements) a
+// usage counter. Increments that would exceed the license's usage limit are
+// rejected with ErrLicenseUsageLimitExceeded, alongside the current usage.
+func (s *licenseService) RecordUsage(ctx context.Context, req UsageRequest) (*UsageResult, error) {
+	if req.Metric == "" {
+		return nil, fmt.Errorf("%w: metric is required", ErrInvalidInput)
+	}
+	if req.Delta == 0 {
+		return nil, fmt.Errorf("%w: delta must not be zero", ErrInvalidInput)
+	}
+
+	license, err := s.getByLicenseKey(ctx, req.ApplicationID, req.LicenseKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err := checkLicenseStatus(license); err != nil {
+		return nil, err
+	}
+
+	result := &UsageResult{Metric: req.Metric}
+	if period, ok := license.UsageResetPeriods[req.Metric].(string); ok {
+		if _, end, ok := usagePeriod(license.StartDate, period, time.Now()); ok {
+			result.ResetsAt = &end
+		}
+	}
+	if limit, ok := usageFloat(license.UsageLimits, req.Metric); ok {
+		result.Limit = &limit
+	}
+
+	// Decrements are always allowed, even when already over the limit
+	limit := result.Limit
+	if req.Delta < 0 {
+		limit = nil
+	}
+
+	used, err := s.repo.IncrementUsage(ctx, license.ID, req.Metric, req.Delta, limit)
+	switch err {
+	case nil:
+	case repository.ErrLimitExceeded:
+		result.setUsed(used)
+		return result, fmt.Errorf("%w: %s exceeds allowed limit", ErrLicenseUsageLimitExceeded, req.Metric)
+	case repository.ErrNotFound:
+		return nil, ErrLicenseInvalid
+	default:
+		return nil, err
+	}
+
+	result.setUsed(used)
+	return result, nil
+}
+
+func (r *UsageResult) setUsed(used float64) {
+	r.Used = used
+	if r.Limit != nil {
+		remaining := *r.Limit - used
+		if remaining < 0 {
+			remaining = 0
+		}
+		r.Remaining = &remaining
+	}
+}
+
+// ResetUsage snapshots and zeroes the usage counters whose reset period has
+// ended and returns how many counters were reset
+func (s *licenseService) ResetUsage(ctx context.Context) (int, error) {
+	licenses, err := s.usageRepo.ListResettable(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	reset := 0
+	for _, license := range licenses {
+		for metric, value := range license.UsageResetPeriods {
+			period, _ := value.(string)
+
+			// Reset at most once per period, snapshotting the one just finished
+			start, end, ok := finishedUsagePeriod(license.StartDate, period, now)
+			if !ok {
+				continue
+			}
+
+			ok, err := s.usageRepo.Reset(ctx, &models.UsageHistory{
+				LicenseID:   license.ID,
+				Metric:      metric,
+				PeriodStart: start,
+				PeriodEnd:   end,
+			})
+			if err != nil {
+				if err == repository.ErrNotFound {
+					continue
+				}
+				return reset, err
+			}
+			if ok {
+				reset++
+			}
+		}
+	}
+
+	return reset, nil
+}
+
+func (s *licenseService) UsageHistory(ctx context.Context, id uuid.UUID, metric string) ([]models.UsageHistory, error) {
+	if _, err := s.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.usageRepo.ListHistory(ctx, id, metric)
+}
+
+// Helper functions
+
+// usagePeriod returns the bounds of the reset period containing at. Periods
+// are anchored to the license start date, so a monthly license started on
+// the 15th resets on the 15th of every month. Anchors past the end of a
+// shorter month reset on its last day: a license started on the 31st resets
+// on February 28th or 29th.
+func usagePeriod(anchor time.Time, period string, at time.Time) (time.Time, time.Time, bool) {
+	var add func(n int) time.Time
+	var n int
+
+	switch period {
+	case models.UsageResetDaily:
+		add = func(n int) time.Time { return anchor.AddDate(0, 0, n) }
+		n = int(at.Sub(anchor) / (24 * time.Hour))
+	case models.UsageResetMonthly:
+		add = func(n int) time.Time { return addMonths(anchor, n) }
+		n = (at.Year()-anchor.Year())*12 + int(at.Month()-anchor.Month())
+	case models.UsageResetYearly:
+		add = func(n int) time.Time { return addMonths(anchor, 12*n) }
+		n = at.Year() - anchor.Year()
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+
+	// The estimate can be off by one around period boundaries
+	for n > 0 && add(n).After(at) {
+		n--
+	}
+	for !add(n + 1).After(at) {
+		n++
+	}
+
+	return add(n), add(n + 1), true
+}
+
+// finishedUsagePeriod returns the bounds of the last period that ended at or
+// before at, whose usage is snapshotted when it is reset. There is none while
+// the first period runs.
+func finishedUsagePeriod(anchor time.Time, period string, at time.Time) (time.Time, time.Time, bool) {
+	start, _, ok := usagePeriod(anchor, period, at)
+	if !ok || !start.After(anchor) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	previous, _, _ := usagePeriod(anchor, period, start.Add(-time.Nanosecond))
+	return previous, start, true
+}
+
+// addMonths adds n months to t, keeping its day of the month unless the
+// target month is shorter, in which case its last day is used
+func addMonths(t time.Time, n int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(n), 1, 0, 0, 0, 0, t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+
+	hour, minute, sec := t.Clock()
+	return time.Date(first.Year(), first.Month(), day, hour, minute, sec, t.Nanosecond(), t.Location())
+}
+
+func validateUsageResetPeriods(periods map[string]any) error {
+	for metric, value := range periods {
+		switch value {
+		case models.UsageResetDaily, models.UsageResetMonthly, models.UsageResetYearly:
+		default:
+			return fmt.Errorf("%w: invalid reset period for metric %s", ErrInvalidInput, metric)
+		}
+	}
+	return nil
+}
+
+// usageFloat reads a numeric usage value, accepting the numeric types
+// produced by JSON decoding
+func usageFloat(usage map[string]any, metric string) (float64, bool) {
+	switch v := usage[metric].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestUsagePeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		anchor    time.Time
+		period    string
+		at        time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+		wantOK    bool
+	}{
+		{
+			name:      "daily",
+			anchor:    date(2024, 1, 10),
+			period:    models.UsageResetDaily,
+			at:        date(2024, 1, 12).Add(15 * time.Hour),
+			wantStart: date(2024, 1, 12),
+			wantEnd:   date(2024, 1, 13),
+			wantOK:    true,
+		},
+		{
+			name:      "daily on the boundary",
+			anchor:    date(2024, 1, 10),
+			period:    models.UsageResetDaily,
+			at:        date(2024, 1, 12),
+			wantStart: date(2024, 1, 12),
+			wantEnd:   date(2024, 1, 13),
+			wantOK:    true,
+		},
+		{
+			name:      "monthly mid-month anchor",
+			anchor:    date(2024, 1, 15),
+			period:    models.UsageResetMonthly,
+			at:        date(2024, 3, 20),
+			wantStart: date(2024, 3, 15),
+			wantEnd:   date(2024, 4, 15),
+			wantOK:    true,
+		},
+		{
+			name:      "monthly before the anchor day",
+			anchor:    date(2024, 1, 15),
+			period:    models.UsageResetMonthly,
+			at:        date(2024, 3, 10),
+			wantStart: date(2024, 2, 15),
+			wantEnd:   date(2024, 3, 15),
+			wantOK:    true,
+		},
+		{
+			name:      "monthly anchor on the 31st in February",
+			anchor:    date(2024, 1, 31),
+			period:    models.UsageResetMonthly,
+			at:        date(2024, 2, 15),
+			wantStart: date(2024, 1, 31),
+			wantEnd:   date(2024, 2, 29),
+			wantOK:    true,
+		},
+		{
+			name:      "monthly anchor on the 31st after a short month",
+			anchor:    date(2024, 1, 31),
+			period:    models.UsageResetMonthly,
+			at:        date(2024, 3, 15),
+			wantStart: date(2024, 2, 29),
+			wantEnd:   date(2024, 3, 31),
+			wantOK:    true,
+		},
+		{
+			name:      "monthly anchor on the 31st in a 30-day month",
+			anchor:    date(2023, 8, 31),
+			period:    models.UsageResetMonthly,
+			at:        date(2023, 10, 5),
+			wantStart: date(2023, 9, 30),
+			wantEnd:   date(2023, 10, 31),
+			wantOK:    true,
+		},
+		{
+			name:      "monthly anchor on the 30th in a non-leap February",
+			anchor:    date(2022, 11, 30),
+			period:    models.UsageResetMonthly,
+			at:        date(2023, 2, 28),
+			wantStart: date(2023, 2, 28),
+			wantEnd:   date(2023, 3, 30),
+			wantOK:    true,
+		},
+		{
+			name:      "monthly across a year",
+			anchor:    date(2023, 11, 20),
+			period:    models.UsageResetMonthly,
+			at:        date(2024, 1, 25),
+			wantStart: date(2024, 1, 20),
+			wantEnd:   date(2024, 2, 20),
+			wantOK:    true,
+		},
+		{
+			name:      "yearly",
+			anchor:    date(2022, 6, 1),
+			period:    models.UsageResetYearly,
+			at:        date(2024, 5, 31),
+			wantStart: date(2023, 6, 1),
+			wantEnd:   date(2024, 6, 1),
+			wantOK:    true,
+		},
+		{
+			name:      "yearly anchor on a leap day",
+			anchor:    date(2024, 2, 29),
+			period:    models.UsageResetYearly,
+			at:        date(2025, 3, 1),
+			wantStart: date(2025, 2, 28),
+			wantEnd:   date(2026, 2, 28),
+			wantOK:    true,
+		},
+		{
+			name:   "unknown period",
+			anchor: date(2024, 1, 1),
+			period: "weekly",
+			at:     date(2024, 2, 1),
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := usagePeriod(tt.anchor, tt.period, tt.at)
+			if ok != tt.wantOK {
+				t.Fatalf("usagePeriod() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("usagePeriod() = [%v, %v), want [%v, %v)", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestFinishedUsagePeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		anchor    time.Time
+		period    string
+		at        time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+		wantOK    bool
+	}{
+		{
+			name:   "first period still running",
+			anchor: date(2024, 1, 15),
+			period: models.UsageResetMonthly,
+			at:     date(2024, 2, 14),
+			wantOK: false,
+		},
+		{
+			name:      "first period just ended",
+			anchor:    date(2024, 1, 15),
+			period:    models.UsageResetMonthly,
+			at:        date(2024, 2, 15),
+			wantStart: date(2024, 1, 15),
+			wantEnd:   date(2024, 2, 15),
+			wantOK:    true,
+		},
+		{
+			name:      "later in the second period",
+			anchor:    date(2024, 1, 15),
+			period:    models.UsageResetMonthly,
+			at:        date(2024, 3, 1),
+			wantStart: date(2024, 1, 15),
+			wantEnd:   date(2024, 2, 15),
+			wantOK:    true,
+		},
+		{
+			name:      "month-end anchor",
+			anchor:    date(2024, 1, 31),
+			period:    models.UsageResetMonthly,
+			at:        date(2024, 3, 1),
+			wantStart: date(2024, 1, 31),
+			wantEnd:   date(2024, 2, 29),
+			wantOK:    true,
+		},
+		{
+			name:      "daily",
+			anchor:    date(2024, 1, 10),
+			period:    models.UsageResetDaily,
+			at:        date(2024, 1, 12).Add(time.Hour),
+			wantStart: date(2024, 1, 11),
+			wantEnd:   date(2024, 1, 12),
+			wantOK:    true,
+		},
+		{
+			name:   "license not started yet",
+			anchor: date(2024, 5, 1),
+			period: models.UsageResetDaily,
+			at:     date(2024, 4, 20),
+			wantOK: false,
+		},
+		{
+			name:   "unknown period",
+			anchor: date(2024, 1, 1),
+			period: "weekly",
+			at:     date(2024, 6, 1),
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := finishedUsagePeriod(tt.anchor, tt.period, tt.at)
+			if ok != tt.wantOK {
+				t.Fatalf("finishedUsagePeriod() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("finishedUsagePeriod() = [%v, %v), want [%v, %v)", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
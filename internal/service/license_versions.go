@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/pkg/semver"
+)
+
+// versionConstraint returns the application versions the license may run,
+// preferring the per-license override over the license type. An empty
+// constraint allows any version.
+func versionConstraint(license *models.License) string {
+	if license.VersionConstraint != "" {
+		return license.VersionConstraint
+	}
+	return license.LicenseType.VersionConstraint
+}
+
+// checkClientVersion reports whether the client's running version is allowed
+// by the license. Constrained licenses require the client to send its version.
+func checkClientVersion(license *models.License, version string) error {
+	constraint := versionConstraint(license)
+	if constraint == "" {
+		return nil
+	}
+	if version == "" {
+		return fmt.Errorf("%w: client version is required", ErrVersionNotAllowed)
+	}
+
+	v, err := semver.Parse(version)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	c, err := semver.ParseConstraint(constraint)
+	if err != nil {
+		return fmt.Errorf("license has an invalid version constraint: %w", err)
+	}
+
+	if !c.Check(v) {
+		return fmt.Errorf("%w: version %s does not satisfy %s", ErrVersionNotAllowed, v, c)
+	}
+	return nil
+}
+
+func validateVersionConstraint(constraint string) error {
+	if constraint == "" {
+		return nil
+	}
+	_, err := semver.ParseConstraint(constraint)
+	return err
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+// outboxLease hides claimed events from other dispatchers while they are
+// being published
+const outboxLease = time.Minute
+
+// OutboxConfig tunes outbox dispatching
+type OutboxConfig struct {
+	// BatchSize is the number of events published per run
+	BatchSize int
+	// The delay before retrying a failed event doubles from MinBackoff up to
+	// MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Retention is how long published events are kept
+	Retention time.Duration
+}
+
+type OutboxService interface {
+	// Publish stores the event in the outbox, inside the transaction carried
+	// by the context when there is one
+	events.Publisher
+	// Dispatch publishes the pending events to the sink and returns how many
+	// succeeded. Events are delivered at least once.
+	Dispatch(ctx context.Context) (int, error)
+	// Cleanup deletes published events past the retention period
+	Cleanup(ctx context.Context) (int64, error)
+}
+
+type outboxService struct {
+	repo   repository.OutboxRepository
+	sink   events.Publisher
+	cfg    OutboxConfig
+	logger *zap.SugaredLogger
+}
+
+func NewOutboxService(
+	repo repository.OutboxRepository,
+	sink events.Publisher,
+	cfg OutboxConfig,
+	logger *zap.SugaredLogger,
+) OutboxService {
+	return &outboxService{
+		repo:   repo,
+		sink:   sink,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (s *outboxService) Publish(ctx context.Context, event events.Event) error {
+	return s.repo.Add(ctx, &models.OutboxEvent{
+		ID:            event.ID,
+		Type:          event.Type,
+		ApplicationID: event.ApplicationID,
+		SubjectID:     event.SubjectID,
+		Data:          event.Data,
+		OccurredAt:    event.OccurredAt,
+		NextAttemptAt: event.OccurredAt,
+	})
+}
+
+func (s *outboxService) Dispatch(ctx context.Context) (int, error) {
+	now := time.Now()
+	pending, err := s.repo.ClaimPending(ctx, now, now.Add(outboxLease), s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, entry := range pending {
+		event := events.Event{
+			ID:            entry.ID,
+			Type:          entry.Type,
+			ApplicationID: entry.ApplicationID,
+			SubjectID:     entry.SubjectID,
+			OccurredAt:    entry.OccurredAt,
+			Data:          entry.Data,
+		}
+
+		if err := s.sink.Publish(ctx, event); err != nil {
+			s.logger.Warnf("Failed to publish %s event %s (attempt %d): %v", entry.Type, entry.ID, entry.Attempts, err)
+			next := time.Now().Add(retryBackoff(entry.Attempts, s.cfg.MinBackoff, s.cfg.MaxBackoff))
+			if err := s.repo.MarkFailed(ctx, entry.ID, err.Error(), next); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := s.repo.MarkPublished(ctx, entry.ID, time.Now()); err != nil {
+			return published, err
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+func (s *outboxService) Cleanup(ctx context.Context) (int64, error) {
+	return s.repo.DeletePublished(ctx, time.Now().Add(-s.cfg.Retention))
+}
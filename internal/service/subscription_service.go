@@ -0,0 +1,439 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+// subscriptionBatchSize bounds the subscriptions settled per run
+const subscriptionBatchSize = 100
+
+// SubscriptionRequest starts a subscription for a license
+type SubscriptionRequest struct {
+	LicenseID uuid.UUID `json:"license_id" binding:"required"`
+	// BillingInterval is monthly or yearly
+	BillingInterval string `json:"billing_interval" binding:"required"`
+	// ExternalID references the subscription in the billing system
+	ExternalID string `json:"external_id"`
+}
+
+type SubscriptionService interface {
+	// Create starts the first billing period when the license's remaining term
+	// runs out, or today if it already has, and moves the license expiry to
+	// the end of that period
+	Create(ctx context.Context, applicationID uuid.UUID, req SubscriptionRequest) (*models.Subscription, error)
+	GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.Subscription, error)
+	List(ctx context.Context, applicationID uuid.UUID, status string) ([]models.Subscription, error)
+	// Renew starts the next billing period, e.g. once a payment succeeded, and
+	// extends the license to its end. Past due subscriptions become active.
+	Renew(ctx context.Context, applicationID, id uuid.UUID) (*models.Subscription, error)
+	// MarkPastDue records a failed payment. The license keeps running until
+	// the end of the period and its grace period.
+	MarkPastDue(ctx context.Context, applicationID, id uuid.UUID) (*models.Subscription, error)
+	// Cancel ends the subscription at the end of the current period, or right
+	// away, which also ends the license today
+	Cancel(ctx context.Context, applicationID, id uuid.UUID, atPeriodEnd bool) (*models.Subscription, error)
+	// Resume withdraws a cancellation scheduled for the period end
+	Resume(ctx context.Context, applicationID, id uuid.UUID) (*models.Subscription, error)
+	// ProcessPeriodEnds settles the active subscriptions whose period ended:
+	// scheduled cancellations end the license and unpaid subscriptions become
+	// past due. It returns how many subscriptions changed.
+	ProcessPeriodEnds(ctx context.Context) (int, error)
+}
+
+type subscriptionService struct {
+	repo        repository.SubscriptionRepository
+	licenseRepo repository.LicenseRepository
+	licenses    LicenseService
+	tx          repository.Transactor
+	publisher   events.Publisher
+	logger      *zap.SugaredLogger
+}
+
+func NewSubscriptionService(
+	repo repository.SubscriptionRepository,
+	licenseRepo repository.LicenseRepository,
+	licenses LicenseService,
+	tx repository.Transactor,
+	publisher events.Publisher,
+	logger *zap.SugaredLogger,
+) SubscriptionService {
+	return &subscriptionService{
+		repo:        repo,
+		licenseRepo: licenseRepo,
+		licenses:    licenses,
+		tx:          tx,
+		publisher:   publisher,
+		logger:      logger,
+	}
+}
+
+// subscriptionChange describes a state transition for the activity log and
+// the event stream
+type subscriptionChange struct {
+	event       string
+	activity    string
+	description string
+}
+
+func (s *subscriptionService) Create(ctx context.Context, applicationID uuid.UUID, req SubscriptionRequest) (*models.Subscription, error) {
+	if !validBillingInterval(req.BillingInterval) {
+		return nil, &ValidationError{Fields: []FieldError{{Field: "billing_interval", Message: "must be one of monthly, yearly"}}}
+	}
+
+	license, err := s.licenseRepo.GetByID(ctx, req.LicenseID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, fmt.Errorf("%w: license not found", ErrInvalidInput)
+		}
+		return nil, err
+	}
+	if license.ApplicationID != applicationID {
+		return nil, fmt.Errorf("%w: license not found", ErrInvalidInput)
+	}
+	if license.IsRevoked {
+		return nil, ErrLicenseRevoked
+	}
+	if license.LicenseType.IsTrial {
+		return nil, fmt.Errorf("%w: trial licenses cannot be subscribed", ErrInvalidInput)
+	}
+
+	if _, err := s.repo.GetByLicense(ctx, license.ID); err == nil {
+		return nil, ErrDuplicateSubscription
+	} else if err != repository.ErrNotFound {
+		return nil, err
+	}
+
+	var subscription *models.Subscription
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Lock the license so the term read here is the one extended
+		license, err := s.licenseRepo.GetForUpdate(ctx, license.ID)
+		if err != nil {
+			return err
+		}
+		if license.IsRevoked {
+			return ErrLicenseRevoked
+		}
+
+		// Time already paid for is kept: billing starts when it runs out
+		start := time.Now()
+		if license.ExpiryDate.After(start) {
+			start = license.ExpiryDate
+		}
+		subscription = &models.Subscription{
+			ApplicationID:      applicationID,
+			LicenseID:          license.ID,
+			BillingInterval:    req.BillingInterval,
+			Status:             models.SubscriptionActive,
+			CurrentPeriodStart: start,
+			CurrentPeriodEnd:   addBillingInterval(start, req.BillingInterval),
+			ExternalID:         req.ExternalID,
+		}
+		license.ExpiryDate = subscription.CurrentPeriodEnd
+		license.IsActive = true
+
+		if _, err := s.repo.Create(ctx, subscription); err != nil {
+			return err
+		}
+		return s.store(ctx, subscription, license, true, &subscriptionChange{
+			event:       events.SubscriptionCreated,
+			activity:    "subscription_created",
+			description: fmt.Sprintf("Subscription started, billed %s", req.BillingInterval),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *subscriptionService) GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.Subscription, error) {
+	subscription, err := s.repo.GetByID(ctx, applicationID, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *subscriptionService) List(ctx context.Context, applicationID uuid.UUID, status string) ([]models.Subscription, error) {
+	switch status {
+	case "", models.SubscriptionActive, models.SubscriptionPastDue, models.SubscriptionCanceled:
+	default:
+		return nil, fmt.Errorf("%w: status must be one of active, past_due, canceled", ErrInvalidInput)
+	}
+	return s.repo.List(ctx, applicationID, status)
+}
+
+func (s *subscriptionService) Renew(ctx context.Context, applicationID, id uuid.UUID) (*models.Subscription, error) {
+	return s.transition(ctx, applicationID, id, func(subscription *models.Subscription, license *models.License) (*subscriptionChange, error) {
+		if subscription.Status == models.SubscriptionCanceled {
+			return nil, ErrSubscriptionCanceled
+		}
+		if subscription.CancelAtPeriodEnd {
+			return nil, fmt.Errorf("%w: subscription is set to cancel at period end, resume it first", ErrInvalidStatus)
+		}
+		if license.IsRevoked {
+			return nil, ErrLicenseRevoked
+		}
+
+		// Periods follow each other, unless the subscription lapsed
+		start := subscription.CurrentPeriodEnd
+		if now := time.Now(); now.After(start) {
+			start = now
+		}
+		subscription.Status = models.SubscriptionActive
+		subscription.CurrentPeriodStart = start
+		subscription.CurrentPeriodEnd = addBillingInterval(start, subscription.BillingInterval)
+
+		license.ExpiryDate = subscription.CurrentPeriodEnd
+		license.IsActive = true
+
+		return &subscriptionChange{
+			event:       events.SubscriptionRenewed,
+			activity:    "subscription_renewed",
+			description: fmt.Sprintf("Subscription renewed until %s", subscription.CurrentPeriodEnd.Format(time.DateOnly)),
+		}, nil
+	})
+}
+
+func (s *subscriptionService) MarkPastDue(ctx context.Context, applicationID, id uuid.UUID) (*models.Subscription, error) {
+	return s.transition(ctx, applicationID, id, func(subscription *models.Subscription, license *models.License) (*subscriptionChange, error) {
+		switch subscription.Status {
+		case models.SubscriptionCanceled:
+			return nil, ErrSubscriptionCanceled
+		case models.SubscriptionPastDue:
+			return nil, nil
+		}
+
+		subscription.Status = models.SubscriptionPastDue
+		return &subscriptionChange{
+			event:       events.SubscriptionPastDue,
+			activity:    "subscription_past_due",
+			description: "Subscription payment is past due",
+		}, nil
+	})
+}
+
+func (s *subscriptionService) Cancel(ctx context.Context, applicationID, id uuid.UUID, atPeriodEnd bool) (*models.Subscription, error) {
+	return s.transition(ctx, applicationID, id, func(subscription *models.Subscription, license *models.License) (*subscriptionChange, error) {
+		if subscription.Status == models.SubscriptionCanceled {
+			return nil, ErrSubscriptionCanceled
+		}
+
+		now := time.Now()
+		if atPeriodEnd && subscription.CurrentPeriodEnd.After(now) {
+			if subscription.CancelAtPeriodEnd {
+				return nil, nil
+			}
+			subscription.CancelAtPeriodEnd = true
+			return &subscriptionChange{
+				event:       events.SubscriptionUpdated,
+				activity:    "subscription_cancel_scheduled",
+				description: fmt.Sprintf("Subscription set to cancel on %s", subscription.CurrentPeriodEnd.Format(time.DateOnly)),
+			}, nil
+		}
+
+		cancelSubscription(subscription, license, now)
+		return &subscriptionChange{
+			event:       events.SubscriptionCanceled,
+			activity:    "subscription_canceled",
+			description: "Subscription canceled, license ended",
+		}, nil
+	})
+}
+
+func (s *subscriptionService) Resume(ctx context.Context, applicationID, id uuid.UUID) (*models.Subscription, error) {
+	return s.transition(ctx, applicationID, id, func(subscription *models.Subscription, license *models.License) (*subscriptionChange, error) {
+		if subscription.Status == models.SubscriptionCanceled {
+			return nil, ErrSubscriptionCanceled
+		}
+		if !subscription.CancelAtPeriodEnd {
+			return nil, nil
+		}
+
+		subscription.CancelAtPeriodEnd = false
+		return &subscriptionChange{
+			event:       events.SubscriptionUpdated,
+			activity:    "subscription_resumed",
+			description: "Scheduled subscription cancellation withdrawn",
+		}, nil
+	})
+}
+
+func (s *subscriptionService) ProcessPeriodEnds(ctx context.Context) (int, error) {
+	ids, err := s.repo.ListPeriodEnded(ctx, time.Now(), subscriptionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, id := range ids {
+		changed := false
+		_, err := s.transition(ctx, uuid.Nil, id, func(subscription *models.Subscription, license *models.License) (*subscriptionChange, error) {
+			// Another replica or a renewal may have got here first
+			now := time.Now()
+			if subscription.Status != models.SubscriptionActive || subscription.CurrentPeriodEnd.After(now) {
+				return nil, nil
+			}
+			changed = true
+
+			if subscription.CancelAtPeriodEnd {
+				cancelSubscription(subscription, license, subscription.CurrentPeriodEnd)
+				return &subscriptionChange{
+					event:       events.SubscriptionCanceled,
+					activity:    "subscription_canceled",
+					description: "Subscription ended at period end, license ended",
+				}, nil
+			}
+
+			subscription.Status = models.SubscriptionPastDue
+			return &subscriptionChange{
+				event:       events.SubscriptionPastDue,
+				activity:    "subscription_past_due",
+				description: "Subscription period ended without renewal",
+			}, nil
+		})
+		if err != nil {
+			return processed, err
+		}
+		if changed {
+			processed++
+		}
+	}
+
+	return processed, nil
+}
+
+// transition locks the subscription, applies the change and stores the
+// subscription and its license together with an activity and an event. A nil
+// change leaves everything as is. uuid.Nil skips the application check, for
+// background jobs.
+func (s *subscriptionService) transition(
+	ctx context.Context,
+	applicationID, id uuid.UUID,
+	apply func(subscription *models.Subscription, license *models.License) (*subscriptionChange, error),
+) (*models.Subscription, error) {
+	var subscription *models.Subscription
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		subscription, err = s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			if err == repository.ErrNotFound {
+				return ErrNotFound
+			}
+			return err
+		}
+		if applicationID != uuid.Nil && subscription.ApplicationID != applicationID {
+			return ErrNotFound
+		}
+
+		// Lock the license too, its term is read and written below
+		license, err := s.licenseRepo.GetForUpdate(ctx, subscription.LicenseID)
+		if err != nil {
+			return err
+		}
+		expiry, active := license.ExpiryDate, license.IsActive
+
+		change, err := apply(subscription, license)
+		if err != nil || change == nil {
+			return err
+		}
+
+		if _, err := s.repo.Update(ctx, subscription); err != nil {
+			return err
+		}
+		licenseChanged := !license.ExpiryDate.Equal(expiry) || license.IsActive != active
+		return s.store(ctx, subscription, license, licenseChanged, change)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// store saves the license term when the subscription changed it, then records
+// the activity and event of the change. Only the term is written, so other
+// license fields changed concurrently are left alone.
+func (s *subscriptionService) store(
+	ctx context.Context,
+	subscription *models.Subscription,
+	license *models.License,
+	licenseChanged bool,
+	change *subscriptionChange,
+) error {
+	if licenseChanged {
+		if err := s.licenseRepo.UpdateTerm(ctx, license.ID, license.ExpiryDate, license.IsActive); err != nil {
+			return err
+		}
+	}
+
+	data := map[string]any{
+		"license_id":           subscription.LicenseID.String(),
+		"status":               subscription.Status,
+		"billing_interval":     subscription.BillingInterval,
+		"current_period_end":   subscription.CurrentPeriodEnd,
+		"cancel_at_period_end": subscription.CancelAtPeriodEnd,
+		"license_expiry_date":  license.ExpiryDate.Format(time.DateOnly),
+	}
+	if subscription.ExternalID != "" {
+		data["external_id"] = subscription.ExternalID
+	}
+
+	// Record subscription activity
+	activity := &models.LicenseActivity{
+		LicenseID:    license.ID,
+		ActivityType: change.activity,
+		Description:  change.description,
+		Metadata:     map[string]interface{}{"subscription_id": subscription.ID.String()},
+	}
+	for key, value := range data {
+		activity.Metadata[key] = value
+	}
+	if err := s.licenses.RecordActivity(ctx, activity); err != nil {
+		return err
+	}
+
+	event := events.New(change.event, subscription.ApplicationID, subscription.ID, data)
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", change.event, err)
+	}
+	return nil
+}
+
+// Helper functions
+
+func validBillingInterval(interval string) bool {
+	return interval == models.BillingIntervalMonthly || interval == models.BillingIntervalYearly
+}
+
+func addBillingInterval(t time.Time, interval string) time.Time {
+	if interval == models.BillingIntervalYearly {
+		return t.AddDate(1, 0, 0)
+	}
+	return t.AddDate(0, 1, 0)
+}
+
+// cancelSubscription ends the subscription and its license at the given time
+func cancelSubscription(subscription *models.Subscription, license *models.License, at time.Time) {
+	subscription.Status = models.SubscriptionCanceled
+	subscription.CancelAtPeriodEnd = false
+	subscription.CanceledAt = &at
+
+	if at.Before(license.ExpiryDate) {
+		license.ExpiryDate = at
+	}
+	license.IsActive = false
+}
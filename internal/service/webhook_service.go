@@ -0,0 +1,388 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/LywwKkA-aD/golicensemanager/internal/events"
+	"github.com/LywwKkA-aD/golicensemanager/internal/models"
+	"github.com/LywwKkA-aD/golicensemanager/internal/repository"
+)
+
+// Webhook request headers
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+	// Response bodies are drained, up to this many bytes, so connections can
+	// be reused; they are never stored
+	maxWebhookResponseBody = 1024
+)
+
+// WebhookConfig tunes webhook delivery
+type WebhookConfig struct {
+	// Timeout bounds a single delivery attempt
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts before a delivery is dead
+	MaxAttempts int
+	// The delay before a retry doubles from MinBackoff up to MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// BatchSize is the number of deliveries sent per run
+	BatchSize int
+}
+
+type WebhookService interface {
+	// Publish queues the event for every endpoint subscribed to it
+	events.Publisher
+	Create(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error)
+	GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.WebhookEndpoint, error)
+	List(ctx context.Context, applicationID uuid.UUID) ([]models.WebhookEndpoint, error)
+	Update(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error)
+	Delete(ctx context.Context, applicationID, id uuid.UUID) error
+	ListDeliveries(ctx context.Context, applicationID, endpointID uuid.UUID, status string) ([]models.WebhookDelivery, error)
+	// Redeliver queues a new delivery of the same event, keeping the original
+	// in the log
+	Redeliver(ctx context.Context, applicationID, endpointID, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+	// DeliverDue sends the deliveries that are due and returns how many
+	// succeeded
+	DeliverDue(ctx context.Context) (int, error)
+}
+
+type webhookService struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+	cfg    WebhookConfig
+	logger *zap.SugaredLogger
+}
+
+func NewWebhookService(repo repository.WebhookRepository, cfg WebhookConfig, logger *zap.SugaredLogger) WebhookService {
+	return &webhookService{
+		repo:   repo,
+		client: newWebhookClient(cfg.Timeout),
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (s *webhookService) Create(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	// Validate input
+	if err := validateWebhookEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = secret
+	endpoint.IsActive = true
+
+	return s.repo.CreateEndpoint(ctx, endpoint)
+}
+
+func (s *webhookService) GetByID(ctx context.Context, applicationID, id uuid.UUID) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.getEndpoint(ctx, applicationID, id)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = ""
+	return endpoint, nil
+}
+
+func (s *webhookService) List(ctx context.Context, applicationID uuid.UUID) ([]models.WebhookEndpoint, error) {
+	endpoints, err := s.repo.ListEndpoints(ctx, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range endpoints {
+		endpoints[i].Secret = ""
+	}
+	return endpoints, nil
+}
+
+func (s *webhookService) Update(ctx context.Context, endpoint *models.WebhookEndpoint) (*models.WebhookEndpoint, error) {
+	// Validate input
+	if err := validateWebhookEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.getEndpoint(ctx, endpoint.ApplicationID, endpoint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Preserve certain fields
+	endpoint.Secret = existing.Secret
+	endpoint.CreatedAt = existing.CreatedAt
+
+	updated, err := s.repo.UpdateEndpoint(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	updated.Secret = ""
+	return updated, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, applicationID, id uuid.UUID) error {
+	if err := s.repo.DeleteEndpoint(ctx, applicationID, id); err != nil {
+		if err == repository.ErrNotFound {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, applicationID, endpointID uuid.UUID, status string) ([]models.WebhookDelivery, error) {
+	if _, err := s.getEndpoint(ctx, applicationID, endpointID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, endpointID, status)
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, applicationID, endpointID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	if _, err := s.getEndpoint(ctx, applicationID, endpointID); err != nil {
+		return nil, err
+	}
+
+	original, err := s.repo.GetDelivery(ctx, endpointID, deliveryID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	// The event ID stays the same, so receivers can deduplicate
+	delivery := &models.WebhookDelivery{
+		EndpointID:    original.EndpointID,
+		EventID:       original.EventID,
+		RedeliveryOf:  &original.ID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := s.repo.CreateDeliveries(ctx, []*models.WebhookDelivery{delivery}); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (s *webhookService) Publish(ctx context.Context, event events.Event) error {
+	endpoints, err := s.repo.ListSubscribed(ctx, event.ApplicationID, event.Type)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	payload, err := eventPayload(event)
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]*models.WebhookDelivery, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		deliveries = append(deliveries, &models.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: event.OccurredAt,
+		})
+	}
+	return s.repo.CreateDeliveries(ctx, deliveries)
+}
+
+func (s *webhookService) DeliverDue(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	// The claimed deliveries are sent one after another, so hold the claim
+	// for as long as the whole batch can take, plus one attempt as a margin
+	leaseUntil := now.Add(time.Duration(s.cfg.BatchSize+1) * s.cfg.Timeout)
+	deliveries, err := s.repo.ClaimDue(ctx, now, leaseUntil, s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		s.attempt(ctx, delivery)
+		if delivery.Status == models.WebhookDeliveryDelivered {
+			delivered++
+		}
+
+		if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+			return delivered, err
+		}
+	}
+
+	return delivered, nil
+}
+
+// attempt sends the delivery once and updates its status. Failed deliveries
+// are retried with exponential backoff until they run out of attempts.
+func (s *webhookService) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	status, err := s.send(ctx, delivery)
+	delivery.ResponseStatus = nil
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+	delivery.LastError = ""
+
+	switch {
+	case err == nil && status >= 200 && status < 300:
+		delivery.Status = models.WebhookDeliveryDelivered
+		return
+	case err != nil:
+		delivery.LastError = err.Error()
+	default:
+		delivery.LastError = fmt.Sprintf("endpoint responded with status %d", status)
+	}
+
+	if delivery.Attempts >= s.cfg.MaxAttempts {
+		delivery.Status = models.WebhookDeliveryDead
+		s.logger.Warnf("Webhook delivery %s to %s is dead after %d attempts: %s",
+			delivery.ID, delivery.Endpoint.URL, delivery.Attempts, delivery.LastError)
+		return
+	}
+	delivery.Status = models.WebhookDeliveryFailed
+	delivery.NextAttemptAt = time.Now().Add(retryBackoff(delivery.Attempts, s.cfg.MinBackoff, s.cfg.MaxBackoff))
+}
+
+func (s *webhookService) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "golicensemanager-webhooks")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(delivery.Endpoint.Secret, time.Now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponseBody))
+	return resp.StatusCode, nil
+}
+
+func (s *webhookService) getEndpoint(ctx context.Context, applicationID, id uuid.UUID) (*models.WebhookEndpoint, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, applicationID, id)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// SignWebhook returns the signature header value for a webhook body:
+// "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">". Receivers
+// recompute the HMAC with the endpoint secret and reject stale timestamps.
+func SignWebhook(secret string, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Helper functions
+
+func validateWebhookEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	if endpoint.ApplicationID == uuid.Nil {
+		return fmt.Errorf("%w: application ID is required", ErrInvalidInput)
+	}
+
+	var fieldErrs []FieldError
+	if u, err := url.Parse(endpoint.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		fieldErrs = append(fieldErrs, FieldError{Field: "url", Message: "must be an absolute http or https URL"})
+	} else if err := checkWebhookHost(ctx, endpoint.URL); err != nil {
+		fieldErrs = append(fieldErrs, FieldError{Field: "url", Message: "must resolve to a public address"})
+	}
+	if len(endpoint.EventTypes) == 0 {
+		fieldErrs = append(fieldErrs, FieldError{Field: "event_types", Message: "is required"})
+	}
+	for i, eventType := range endpoint.EventTypes {
+		if eventType != "*" && !slices.Contains(events.Types, eventType) {
+			fieldErrs = append(fieldErrs, FieldError{
+				Field:   fmt.Sprintf("event_types[%d]", i),
+				Message: fmt.Sprintf("must be * or one of %v", events.Types),
+			})
+		}
+	}
+
+	if len(fieldErrs) > 0 {
+		return &ValidationError{Fields: fieldErrs}
+	}
+	return nil
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return webhookSecretPrefix + hex.EncodeToString(buf), nil
+}
+
+// eventPayload converts the event into the JSON object sent to endpoints
+func eventPayload(event events.Event) (map[string]any, error) {
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+	return payload, nil
+}
+
+// retryBackoff returns the delay before the next attempt, doubling from minDelay
+// with every attempt made, up to maxDelay
+func retryBackoff(attempts int, minDelay, maxDelay time.Duration) time.Duration {
+	delay := minDelay
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		attempts int
+		minDelay time.Duration
+		maxDelay time.Duration
+		want     time.Duration
+	}{
+		{name: "first attempt", attempts: 1, minDelay: time.Minute, maxDelay: time.Hour, want: time.Minute},
+		{name: "no attempts yet", attempts: 0, minDelay: time.Minute, maxDelay: time.Hour, want: time.Minute},
+		{name: "second attempt doubles", attempts: 2, minDelay: time.Minute, maxDelay: time.Hour, want: 2 * time.Minute},
+		{name: "fifth attempt", attempts: 5, minDelay: time.Minute, maxDelay: time.Hour, want: 16 * time.Minute},
+		{name: "capped at the maximum", attempts: 8, minDelay: time.Minute, maxDelay: time.Hour, want: time.Hour},
+		{name: "many attempts do not overflow", attempts: 1000, minDelay: time.Minute, maxDelay: time.Hour, want: time.Hour},
+		{name: "minimum above the maximum", attempts: 1, minDelay: 2 * time.Hour, maxDelay: time.Hour, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryBackoff(tt.attempts, tt.minDelay, tt.maxDelay); got != tt.want {
+				t.Errorf("retryBackoff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
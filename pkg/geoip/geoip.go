@@ -0,0 +1,122 @@
+// Package geoip resolves IP addresses to approximate coordinates using a
+// database of network ranges.
+//
+// The database is a CSV file of "network,latitude,longitude" rows, e.g.
+// "203.0.113.0/24,52.52,13.405". Lines starting with "#" and a header row are
+// skipped. The most specific network containing an address wins.
+package geoip
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const earthRadiusKm = 6371.0
+
+// Location is a point on the globe in decimal degrees
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type entry struct {
+	network  *net.IPNet
+	ones     int
+	location Location
+}
+
+// Database maps networks to locations
+type Database struct {
+	entries []entry
+}
+
+// Load reads a database from a CSV file
+func Load(path string) (*Database, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geoip database: %w", err)
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads a database in CSV format
+func Parse(r io.Reader) (*Database, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	db := &Database{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse geoip database: %w", err)
+		}
+
+		_, network, err := net.ParseCIDR(strings.TrimSpace(record[0]))
+		if err != nil {
+			if line == 1 {
+				continue // header row
+			}
+			return nil, fmt.Errorf("geoip database line %d: invalid network %q", line, record[0])
+		}
+		lat, latErr := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		lon, lonErr := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if latErr != nil || lonErr != nil || math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+			return nil, fmt.Errorf("geoip database line %d: invalid coordinates", line)
+		}
+
+		ones, _ := network.Mask.Size()
+		db.entries = append(db.entries, entry{
+			network:  network,
+			ones:     ones,
+			location: Location{Latitude: lat, Longitude: lon},
+		})
+	}
+
+	// Most specific networks first, so the first match is the best one
+	sort.SliceStable(db.entries, func(i, j int) bool {
+		return db.entries[i].ones > db.entries[j].ones
+	})
+
+	return db, nil
+}
+
+// Locate returns the location of the address, if it is in the database
+func (d *Database) Locate(ip string) (Location, bool) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return Location{}, false
+	}
+	for _, e := range d.entries {
+		if e.network.Contains(addr) {
+			return e.location, true
+		}
+	}
+	return Location{}, false
+}
+
+// Distance returns the great-circle distance between two locations in km
+func Distance(a, b Location) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
@@ -0,0 +1,205 @@
+// Package licensefile encodes and verifies offline license files.
+//
+// A license file is a JSON document signed with an Ed25519 private key held by
+// the license server. Applications embed the matching public key and call
+// Verify to check a license without contacting the server.
+package licensefile
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/LywwKkA-aD/golicensemanager/pkg/semver"
+)
+
+// FormatVersion is the current version of the license file document
+const FormatVersion = 1
+
+var (
+	// ErrMalformed is returned when a license file cannot be decoded
+	ErrMalformed = errors.New("malformed license file")
+
+	// ErrInvalidSignature is returned when the signature does not match the document
+	ErrInvalidSignature = errors.New("invalid license file signature")
+
+	// ErrUnknownKey is returned when the document was signed with a key that is not in the key set
+	ErrUnknownKey = errors.New("license file signed with unknown key")
+
+	// ErrExpired is returned when the license file is past its expiry date
+	ErrExpired = errors.New("license file has expired")
+
+	// ErrNotYetValid is returned when the license file start date is in the future
+	ErrNotYetValid = errors.New("license file is not yet valid")
+
+	// ErrVersionNotAllowed is returned when the application version is outside the version constraint
+	ErrVersionNotAllowed = errors.New("application version is not allowed by license file")
+)
+
+// Document is the signed content of a license file
+type Document struct {
+	Version         int            `json:"version"`
+	KeyID           string         `json:"kid,omitempty"`
+	LicenseID       uuid.UUID      `json:"license_id"`
+	LicenseKey      string         `json:"license_key"`
+	ApplicationID   uuid.UUID      `json:"application_id"`
+	ClientID        uuid.UUID      `json:"client_id"`
+	ClientName      string         `json:"client_name,omitempty"`
+	LicenseTypeID   uuid.UUID      `json:"license_type_id"`
+	LicenseTypeName string         `json:"license_type_name,omitempty"`
+	Features        map[string]any `json:"features"`
+	UsageLimits     map[string]any `json:"usage_limits"`
+	// VersionConstraint lists the allowed application versions, e.g. ">=2.0.0 <3.0.0"
+	VersionConstraint string    `json:"version_constraint,omitempty"`
+	StartDate         time.Time `json:"start_date"`
+	ExpiryDate        time.Time `json:"expiry_date"`
+	IssuedAt          time.Time `json:"issued_at"`
+	// Addons are add-on license types attached to the license
+	Addons []Addon `json:"addons,omitempty"`
+}
+
+// Addon is an add-on attached to the license. Its features only apply within
+// its own validity period.
+type Addon struct {
+	LicenseTypeID   uuid.UUID      `json:"license_type_id"`
+	LicenseTypeName string         `json:"license_type_name,omitempty"`
+	Features        map[string]any `json:"features"`
+	StartDate       time.Time      `json:"start_date"`
+	ExpiryDate      time.Time      `json:"expiry_date"`
+}
+
+// CheckValidity reports whether the add-on is within its validity period at the given time
+func (a *Addon) CheckValidity(at time.Time) error {
+	if at.Before(a.StartDate) {
+		return ErrNotYetValid
+	}
+	if at.After(a.ExpiryDate) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// CheckValidity reports whether the document is within its validity period at the given time
+func (d *Document) CheckValidity(at time.Time) error {
+	if at.Before(d.StartDate) {
+		return ErrNotYetValid
+	}
+	if at.After(d.ExpiryDate) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// CheckVersion reports whether the running application version satisfies the
+// document's version constraint. Documents without a constraint allow any version.
+func (d *Document) CheckVersion(version string) error {
+	if d.VersionConstraint == "" {
+		return nil
+	}
+
+	ok, err := semver.Satisfies(version, d.VersionConstraint)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrVersionNotAllowed
+	}
+	return nil
+}
+
+// Sign serializes the document and signs it with the given private key.
+// The result has the form "<base64url(document)>.<base64url(signature)>".
+func Sign(doc *Document, privateKey ed25519.PrivateKey) (string, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return "", fmt.Errorf("invalid private key size: %d", len(privateKey))
+	}
+	if doc.Version == 0 {
+		doc.Version = FormatVersion
+	}
+
+	payload, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode license document: %w", err)
+	}
+
+	signature := ed25519.Sign(privateKey, payload)
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verify checks the signature of a license file against the given public key
+// and returns the decoded document. It does not check the validity period;
+// use Document.CheckValidity for that.
+func Verify(file string, publicKey ed25519.PublicKey) (*Document, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key size: %d", len(publicKey))
+	}
+
+	payload, signature, err := decode(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ed25519.Verify(publicKey, payload, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var doc Document
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	return &doc, nil
+}
+
+// KeySet maps key IDs to the public keys used to verify license files
+type KeySet map[string]ed25519.PublicKey
+
+// VerifyWithKeySet picks the public key named by the document's key ID from
+// the key set and verifies the license file with it
+func VerifyWithKeySet(file string, keys KeySet) (*Document, error) {
+	payload, _, err := decode(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var header struct {
+		KeyID string `json:"kid"`
+	}
+	if err := json.Unmarshal(payload, &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	publicKey, ok := keys[header.KeyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return Verify(file, publicKey)
+}
+
+func decode(file string) (payload, signature []byte, err error) {
+	parts := strings.Split(strings.TrimSpace(file), ".")
+	if len(parts) != 2 {
+		return nil, nil, ErrMalformed
+	}
+
+	payload, err = base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	signature, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	return payload, signature, nil
+}
@@ -0,0 +1,261 @@
+package licensefile
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testKey(seed byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize))
+}
+
+func testDocument(kid string) *Document {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &Document{
+		KeyID:             kid,
+		LicenseID:         uuid.MustParse("6f1c2b1e-8a51-4f7e-9d1a-3c2b1a0f9e8d"),
+		LicenseKey:        "ACME-7KQ2M-XH4PD-9TRWC-N3FJV",
+		ApplicationID:     uuid.MustParse("0b7e5d4c-3a2f-4e1d-8c9b-7a6f5e4d3c2b"),
+		ClientID:          uuid.MustParse("9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"),
+		ClientName:        "Acme Corp",
+		LicenseTypeID:     uuid.MustParse("1d2c3b4a-5f6e-4d7c-8b9a-0f1e2d3c4b5a"),
+		LicenseTypeName:   "Professional",
+		Features:          map[string]any{"export": true, "max_projects": float64(10)},
+		UsageLimits:       map[string]any{"api_calls": float64(1000)},
+		VersionConstraint: "^2.0.0",
+		StartDate:         start,
+		ExpiryDate:        start.AddDate(1, 0, 0),
+		IssuedAt:          start,
+	}
+}
+
+// resign replaces the payload of a license file and keeps its signature
+func resign(t *testing.T, file string, edit func(payload string) string) string {
+	t.Helper()
+
+	parts := strings.Split(file, ".")
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	edited := edit(string(payload))
+	if edited == string(payload) {
+		t.Fatalf("edit did not change the payload")
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(edited)) + "." + parts[1]
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	key := testKey(1)
+	doc := testDocument("kid-1")
+
+	file, err := Sign(doc, key)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		verify func() (*Document, error)
+	}{
+		{
+			name: "public key",
+			verify: func() (*Document, error) {
+				return Verify(file, key.Public().(ed25519.PublicKey))
+			},
+		},
+		{
+			name: "key set",
+			verify: func() (*Document, error) {
+				return VerifyWithKeySet(file, KeySet{
+					"kid-0": testKey(0).Public().(ed25519.PublicKey),
+					"kid-1": key.Public().(ed25519.PublicKey),
+				})
+			},
+		},
+		{
+			name: "surrounding whitespace",
+			verify: func() (*Document, error) {
+				return Verify("  "+file+"\n", key.Public().(ed25519.PublicKey))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.verify()
+			if err != nil {
+				t.Fatalf("verify error = %v", err)
+			}
+			if got.Version != FormatVersion {
+				t.Errorf("Version = %d, want %d", got.Version, FormatVersion)
+			}
+			if got.KeyID != doc.KeyID || got.LicenseID != doc.LicenseID || got.LicenseKey != doc.LicenseKey {
+				t.Errorf("identity = %q %s %q, want %q %s %q",
+					got.KeyID, got.LicenseID, got.LicenseKey, doc.KeyID, doc.LicenseID, doc.LicenseKey)
+			}
+			if got.ClientName != doc.ClientName || got.VersionConstraint != doc.VersionConstraint {
+				t.Errorf("client/constraint = %q %q, want %q %q",
+					got.ClientName, got.VersionConstraint, doc.ClientName, doc.VersionConstraint)
+			}
+			if !got.ExpiryDate.Equal(doc.ExpiryDate) {
+				t.Errorf("ExpiryDate = %s, want %s", got.ExpiryDate, doc.ExpiryDate)
+			}
+			if got.Features["max_projects"] != float64(10) || got.Features["export"] != true {
+				t.Errorf("Features = %v, want %v", got.Features, doc.Features)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	key := testKey(1)
+	publicKey := key.Public().(ed25519.PublicKey)
+
+	file, err := Sign(testDocument("kid-1"), key)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	parts := strings.Split(file, ".")
+
+	tests := []struct {
+		name    string
+		file    string
+		wantErr error
+	}{
+		{
+			name: "extended expiry",
+			file: resign(t, file, func(payload string) string {
+				return strings.Replace(payload, `"expiry_date":"2027`, `"expiry_date":"2099`, 1)
+			}),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "raised limit",
+			file: resign(t, file, func(payload string) string {
+				return strings.Replace(payload, `"api_calls":1000`, `"api_calls":1000000`, 1)
+			}),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "removed version constraint",
+			file: resign(t, file, func(payload string) string {
+				return strings.Replace(payload, `"version_constraint":"^2.0.0",`, ``, 1)
+			}),
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "signature of another file",
+			file:    parts[0] + "." + strings.Split(mustSign(t, testDocument("kid-2"), key), ".")[1],
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "truncated signature",
+			file:    parts[0] + "." + parts[1][:len(parts[1])-4],
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "missing signature",
+			file:    parts[0],
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "extra segment",
+			file:    file + ".extra",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "invalid encoding",
+			file:    parts[0] + ".not*base64",
+			wantErr: ErrMalformed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Verify(tt.file, publicKey)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyWithKeySetWrongKey(t *testing.T) {
+	signingKey := testKey(1)
+	otherKey := testKey(2)
+
+	file, err := Sign(testDocument("kid-1"), signingKey)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		keys    KeySet
+		wantErr error
+	}{
+		{
+			name:    "kid not in key set",
+			file:    file,
+			keys:    KeySet{"kid-2": otherKey.Public().(ed25519.PublicKey)},
+			wantErr: ErrUnknownKey,
+		},
+		{
+			name:    "empty key set",
+			file:    file,
+			keys:    KeySet{},
+			wantErr: ErrUnknownKey,
+		},
+		{
+			name:    "kid mapped to another key",
+			file:    file,
+			keys:    KeySet{"kid-1": otherKey.Public().(ed25519.PublicKey)},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "kid rewritten to a trusted key",
+			file: resign(t, file, func(payload string) string {
+				return strings.Replace(payload, `"kid":"kid-1"`, `"kid":"kid-2"`, 1)
+			}),
+			keys: KeySet{
+				"kid-1": signingKey.Public().(ed25519.PublicKey),
+				"kid-2": otherKey.Public().(ed25519.PublicKey),
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "document without kid",
+			file:    mustSign(t, testDocument(""), signingKey),
+			keys:    KeySet{"kid-1": signingKey.Public().(ed25519.PublicKey)},
+			wantErr: ErrUnknownKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyWithKeySet(tt.file, tt.keys)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyWithKeySet() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func mustSign(t *testing.T, doc *Document, key ed25519.PrivateKey) string {
+	t.Helper()
+
+	file, err := Sign(doc, key)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	return file
+}
@@ -0,0 +1,228 @@
+// Package licensekey generates and checks license keys.
+//
+// Grouped keys are made of random characters from an alphabet, split into
+// dash separated groups behind an optional prefix, e.g.
+// "ACME-7KQ2M-XH4PD-9TRWC-N3FJV". The last character is a Luhn mod N check
+// character, so most typing mistakes are caught without a database lookup.
+package licensekey
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DefaultAlphabet is a base32 alphabet without the easily confused I, O, 0 and 1
+const DefaultAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const (
+	// DefaultGroups is the default number of groups in a grouped key
+	DefaultGroups = 4
+	// DefaultGroupSize is the default number of characters per group
+	DefaultGroupSize = 5
+
+	maxPrefixLength = 16
+	maxGroups       = 10
+	maxGroupSize    = 10
+	minKeyLength    = 12
+	hexKeyLength    = 32
+)
+
+var (
+	// ErrInvalidFormat is returned when a key does not match the expected format
+	ErrInvalidFormat = errors.New("license key has an invalid format")
+
+	// ErrChecksumMismatch is returned when the check character does not match the key
+	ErrChecksumMismatch = errors.New("license key checksum mismatch")
+)
+
+// Generator creates and checks license keys of one format
+type Generator interface {
+	// Generate returns a new random key
+	Generate() (string, error)
+	// Normalize canonicalizes a key as entered by a user
+	Normalize(key string) string
+	// Check reports whether the key is well formed
+	Check(key string) error
+}
+
+// IsLegacy reports whether the key is a legacy 32 character hex key. Legacy
+// keys carry no checksum and stay valid when an application changes format.
+func IsLegacy(key string) bool {
+	if len(key) != hexKeyLength {
+		return false
+	}
+	for _, c := range key {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Hex generates the legacy 32 character lowercase hex keys
+type Hex struct{}
+
+func (Hex) Generate() (string, error) {
+	buf := make([]byte, hexKeyLength/2)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (Hex) Normalize(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+func (Hex) Check(key string) error {
+	if !IsLegacy(key) {
+		return ErrInvalidFormat
+	}
+	return nil
+}
+
+// Grouped generates grouped keys with an embedded check character
+type Grouped struct {
+	prefix    string
+	groups    int
+	groupSize int
+	alphabet  string
+}
+
+// NewGrouped validates the format parameters and returns a grouped key
+// generator. Zero values select the defaults.
+func NewGrouped(prefix string, groups, groupSize int, alphabet string) (*Grouped, error) {
+	if groups == 0 {
+		groups = DefaultGroups
+	}
+	if groupSize == 0 {
+		groupSize = DefaultGroupSize
+	}
+	if alphabet == "" {
+		alphabet = DefaultAlphabet
+	}
+	prefix = strings.ToUpper(prefix)
+
+	if len(prefix) > maxPrefixLength || !isAlphanumeric(prefix) {
+		return nil, fmt.Errorf("prefix must be at most %d letters or digits", maxPrefixLength)
+	}
+	if groups < 1 || groups > maxGroups {
+		return nil, fmt.Errorf("groups must be between 1 and %d", maxGroups)
+	}
+	if groupSize < 2 || groupSize > maxGroupSize {
+		return nil, fmt.Errorf("group size must be between 2 and %d", maxGroupSize)
+	}
+	if groups*groupSize < minKeyLength {
+		return nil, fmt.Errorf("keys must have at least %d characters", minKeyLength)
+	}
+	if len(alphabet) < 2 || !isAlphanumeric(alphabet) || strings.ToUpper(alphabet) != alphabet {
+		return nil, errors.New("alphabet must have at least 2 uppercase letters or digits")
+	}
+	for i := range alphabet {
+		if strings.IndexByte(alphabet, alphabet[i]) != i {
+			return nil, fmt.Errorf("alphabet contains %q more than once", alphabet[i])
+		}
+	}
+
+	return &Grouped{
+		prefix:    prefix,
+		groups:    groups,
+		groupSize: groupSize,
+		alphabet:  alphabet,
+	}, nil
+}
+
+func (g *Grouped) Generate() (string, error) {
+	length := g.groups * g.groupSize
+	body := make([]byte, length)
+
+	max := big.NewInt(int64(len(g.alphabet)))
+	for i := 0; i < length-1; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		body[i] = g.alphabet[n.Int64()]
+	}
+	body[length-1] = g.checkChar(body[:length-1])
+
+	var b strings.Builder
+	if g.prefix != "" {
+		b.WriteString(g.prefix)
+		b.WriteByte('-')
+	}
+	for i := 0; i < g.groups; i++ {
+		if i > 0 {
+			b.WriteByte('-')
+		}
+		b.Write(body[i*g.groupSize : (i+1)*g.groupSize])
+	}
+
+	return b.String(), nil
+}
+
+func (g *Grouped) Normalize(key string) string {
+	return strings.ToUpper(strings.TrimSpace(key))
+}
+
+func (g *Grouped) Check(key string) error {
+	if g.prefix != "" {
+		if !strings.HasPrefix(key, g.prefix+"-") {
+			return ErrInvalidFormat
+		}
+		key = key[len(g.prefix)+1:]
+	}
+
+	parts := strings.Split(key, "-")
+	if len(parts) != g.groups {
+		return ErrInvalidFormat
+	}
+
+	body := make([]byte, 0, g.groups*g.groupSize)
+	for _, part := range parts {
+		if len(part) != g.groupSize {
+			return ErrInvalidFormat
+		}
+		for i := 0; i < len(part); i++ {
+			if strings.IndexByte(g.alphabet, part[i]) < 0 {
+				return ErrInvalidFormat
+			}
+		}
+		body = append(body, part...)
+	}
+
+	if g.checkChar(body[:len(body)-1]) != body[len(body)-1] {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// checkChar computes the Luhn mod N check character over the input, which
+// detects all single character errors and most adjacent transpositions
+func (g *Grouped) checkChar(input []byte) byte {
+	n := len(g.alphabet)
+	factor := 2
+	sum := 0
+
+	for i := len(input) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(g.alphabet, input[i])
+		addend = addend/n + addend%n
+		sum += addend
+		factor = 3 - factor
+	}
+
+	return g.alphabet[(n-sum%n)%n]
+}
+
+func isAlphanumeric(s string) bool {
+	for _, c := range s {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,183 @@
+package licensekey
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func mustGrouped(t *testing.T, prefix string, groups, groupSize int, alphabet string) *Grouped {
+	t.Helper()
+
+	g, err := NewGrouped(prefix, groups, groupSize, alphabet)
+	if err != nil {
+		t.Fatalf("NewGrouped() error = %v", err)
+	}
+	return g
+}
+
+func TestGroupedDetectsSingleCharacterTypos(t *testing.T) {
+	tests := []struct {
+		name      string
+		prefix    string
+		groups    int
+		groupSize int
+		alphabet  string
+	}{
+		{name: "defaults"},
+		{name: "prefix", prefix: "acme"},
+		{name: "long groups", groups: 3, groupSize: 8},
+		{name: "hex alphabet", alphabet: "0123456789ABCDEF"},
+		{name: "digits", groups: 4, groupSize: 4, alphabet: "0123456789"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := mustGrouped(t, tt.prefix, tt.groups, tt.groupSize, tt.alphabet)
+
+			for n := 0; n < 20; n++ {
+				key, err := g.Generate()
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				if err := g.Check(key); err != nil {
+					t.Fatalf("Check(%q) error = %v", key, err)
+				}
+
+				// Replace every character of the body with every other
+				// character of the alphabet
+				bodyStart := 0
+				if g.prefix != "" {
+					bodyStart = len(g.prefix) + 1
+				}
+				for i := bodyStart; i < len(key); i++ {
+					if key[i] == '-' {
+						continue
+					}
+					for j := 0; j < len(g.alphabet); j++ {
+						if g.alphabet[j] == key[i] {
+							continue
+						}
+						typo := key[:i] + string(g.alphabet[j]) + key[i+1:]
+						if err := g.Check(typo); !errors.Is(err, ErrChecksumMismatch) {
+							t.Fatalf("Check(%q) of %q error = %v, want %v", typo, key, err, ErrChecksumMismatch)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGroupedCheck(t *testing.T) {
+	g := mustGrouped(t, "ACME", 0, 0, "")
+	key, err := g.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	body := strings.TrimPrefix(key, "ACME-")
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr error
+	}{
+		{name: "generated", key: key},
+		{name: "lowercase normalized", key: g.Normalize(" " + strings.ToLower(key) + "\t")},
+		{name: "lowercase", key: strings.ToLower(key), wantErr: ErrInvalidFormat},
+		{name: "missing prefix", key: body, wantErr: ErrInvalidFormat},
+		{name: "other prefix", key: "ACMF-" + body, wantErr: ErrInvalidFormat},
+		{name: "missing group", key: key[:strings.LastIndexByte(key, '-')], wantErr: ErrInvalidFormat},
+		{name: "missing character", key: key[:len(key)-1], wantErr: ErrInvalidFormat},
+		{name: "missing dashes", key: "ACME-" + strings.ReplaceAll(body, "-", ""), wantErr: ErrInvalidFormat},
+		{name: "character outside alphabet", key: key[:len(key)-1] + "0", wantErr: ErrInvalidFormat},
+		{name: "empty", key: "", wantErr: ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := g.Check(tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check(%q) error = %v, want %v", tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGroupedDetectsAdjacentTranspositions(t *testing.T) {
+	g := mustGrouped(t, "", 0, 0, "")
+
+	// Luhn mod N misses some transpositions, so only count them
+	missed, total := 0, 0
+	for n := 0; n < 200; n++ {
+		key, err := g.Generate()
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		for i := 0; i+1 < len(key); i++ {
+			if key[i] == '-' || key[i+1] == '-' || key[i] == key[i+1] {
+				continue
+			}
+			swapped := key[:i] + string(key[i+1]) + string(key[i]) + key[i+2:]
+			total++
+			if g.Check(swapped) == nil {
+				missed++
+			}
+		}
+	}
+
+	if missed*20 > total {
+		t.Errorf("missed %d of %d adjacent transpositions, want at most 5%%", missed, total)
+	}
+}
+
+func TestNewGroupedRejectsInvalidFormats(t *testing.T) {
+	tests := []struct {
+		name      string
+		prefix    string
+		groups    int
+		groupSize int
+		alphabet  string
+	}{
+		{name: "prefix with dash", prefix: "AC-ME"},
+		{name: "prefix too long", prefix: strings.Repeat("A", maxPrefixLength+1)},
+		{name: "too many groups", groups: maxGroups + 1},
+		{name: "group too small", groupSize: 1, groups: 10},
+		{name: "group too large", groupSize: maxGroupSize + 1},
+		{name: "key too short", groups: 2, groupSize: 5},
+		{name: "lowercase alphabet", alphabet: "abcdef"},
+		{name: "single character alphabet", alphabet: "A"},
+		{name: "repeated character", alphabet: "ABCA"},
+		{name: "symbol in alphabet", alphabet: "ABC-"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGrouped(tt.prefix, tt.groups, tt.groupSize, tt.alphabet); err == nil {
+				t.Errorf("NewGrouped() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestHex(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr error
+	}{
+		{name: "legacy key", key: "0123456789abcdef0123456789abcdef"},
+		{name: "uppercase normalized", key: Hex{}.Normalize(" 0123456789ABCDEF0123456789ABCDEF ")},
+		{name: "uppercase", key: "0123456789ABCDEF0123456789ABCDEF", wantErr: ErrInvalidFormat},
+		{name: "too short", key: "0123456789abcdef", wantErr: ErrInvalidFormat},
+		{name: "not hex", key: "0123456789abcdef0123456789abcdeg", wantErr: ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := (Hex{}).Check(tt.key); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Check(%q) error = %v, want %v", tt.key, err, tt.wantErr)
+			}
+		})
+	}
+}
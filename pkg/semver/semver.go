@@ -0,0 +1,292 @@
+// Package semver parses semantic versions and checks them against version
+// constraints.
+//
+// A constraint is a list of comparators separated by spaces (or commas), all
+// of which must match, e.g. ">=2.0.0 <3.0.0". Alternatives are separated by
+// "||". Supported operators are =, !=, >, >=, <, <=, ^ (same major version,
+// or same minor for 0.x) and ~ (same minor version). Missing minor and patch
+// numbers default to zero, and a leading "v" is ignored.
+//
+// As with npm, prerelease versions are excluded unless they are asked for: a
+// prerelease only satisfies an alternative that has a comparator with a
+// prerelease of the same major, minor and patch version. ">=1.2.0-beta"
+// matches "1.2.0-rc.1" but not "1.3.0-rc.1", and "<2.0.0" does not match
+// "2.0.0-rc.1".
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidVersion is returned when a version cannot be parsed
+	ErrInvalidVersion = errors.New("invalid version")
+
+	// ErrInvalidConstraint is returned when a constraint cannot be parsed
+	ErrInvalidConstraint = errors.New("invalid version constraint")
+)
+
+// Version is a parsed semantic version. Build metadata is ignored.
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+}
+
+// Parse parses a version such as "2.1.0", "v2.1" or "3.0.0-rc.1"
+func Parse(s string) (Version, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+
+	var v Version
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		if i == len(s)-1 {
+			return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+		}
+		v.Prerelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 || parts[0] == "" {
+		return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+
+	numbers := make([]uint64, 3)
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+		}
+		numbers[i] = n
+	}
+	v.Major, v.Minor, v.Patch = numbers[0], numbers[1], numbers[2]
+
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 when v is lower than, equal to or greater than o
+func (v Version) Compare(o Version) int {
+	if c := compareUint(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v.Patch, o.Patch); c != 0 {
+		return c
+	}
+
+	// A release is greater than any of its prereleases
+	switch {
+	case len(v.Prerelease) == 0 && len(o.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(o.Prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v.Prerelease) && i < len(o.Prerelease); i++ {
+		if c := compareIdentifier(v.Prerelease[i], o.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(v.Prerelease)), uint64(len(o.Prerelease)))
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	return s
+}
+
+type comparator struct {
+	op      string
+	version Version
+}
+
+func (c comparator) matches(v Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	}
+	return false
+}
+
+// Constraint is a parsed version constraint
+type Constraint struct {
+	raw string
+	// alternatives are OR-ed; the comparators of each are AND-ed
+	alternatives [][]comparator
+}
+
+// ParseConstraint parses a constraint such as ">=2.0.0 <3.0.0" or "^1.4 || ^2"
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: strings.TrimSpace(s)}
+	if c.raw == "" {
+		return nil, fmt.Errorf("%w: empty constraint", ErrInvalidConstraint)
+	}
+
+	for _, alternative := range strings.Split(c.raw, "||") {
+		fields := strings.Fields(strings.ReplaceAll(alternative, ",", " "))
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidConstraint, s)
+		}
+
+		var comparators []comparator
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+
+			// Allow a space between operator and version, e.g. ">= 2.0"
+			if isOperator(field) && i+1 < len(fields) {
+				i++
+				field += fields[i]
+			}
+
+			parsed, err := parseComparator(field)
+			if err != nil {
+				return nil, err
+			}
+			comparators = append(comparators, parsed...)
+		}
+		c.alternatives = append(c.alternatives, comparators)
+	}
+
+	return c, nil
+}
+
+// Check reports whether the version satisfies the constraint
+func (c *Constraint) Check(v Version) bool {
+	for _, comparators := range c.alternatives {
+		if len(v.Prerelease) > 0 && !allowsPrerelease(comparators, v) {
+			continue
+		}
+
+		ok := true
+		for _, comp := range comparators {
+			if !comp.matches(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsPrerelease reports whether one of the comparators has a prerelease of
+// the same major, minor and patch version as v
+func allowsPrerelease(comparators []comparator, v Version) bool {
+	for _, comp := range comparators {
+		cv := comp.version
+		if len(cv.Prerelease) > 0 && cv.Major == v.Major && cv.Minor == v.Minor && cv.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// Satisfies parses the version and constraint and reports whether they match
+func Satisfies(version, constraint string) (bool, error) {
+	v, err := Parse(version)
+	if err != nil {
+		return false, err
+	}
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+	return c.Check(v), nil
+}
+
+func parseComparator(s string) ([]comparator, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", "!=", "==", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(s, candidate) {
+			op = candidate
+			break
+		}
+	}
+
+	v, err := Parse(s[len(op):])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidConstraint, s)
+	}
+
+	switch op {
+	case "", "==":
+		return []comparator{{op: "=", version: v}}, nil
+	case "^":
+		upper := Version{Major: v.Major + 1}
+		if v.Major == 0 {
+			upper = Version{Minor: v.Minor + 1}
+		}
+		return []comparator{{op: ">=", version: v}, {op: "<", version: upper}}, nil
+	case "~":
+		upper := Version{Major: v.Major, Minor: v.Minor + 1}
+		return []comparator{{op: ">=", version: v}, {op: "<", version: upper}}, nil
+	default:
+		return []comparator{{op: op, version: v}}, nil
+	}
+}
+
+func isOperator(s string) bool {
+	switch s {
+	case ">=", "<=", "!=", "==", ">", "<", "=", "^", "~":
+		return true
+	}
+	return false
+}
+
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareIdentifier compares prerelease identifiers: numeric identifiers
+// compare numerically and sort before alphanumeric ones
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareUint(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
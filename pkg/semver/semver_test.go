@@ -0,0 +1,173 @@
+package semver
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		// Caret: same major version
+		{"^1.2.3", "1.2.3", true},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "1.2.2", false},
+		{"^1.2.3", "2.0.0", false},
+		{"^1", "1.99.99", true},
+		{"^1", "0.9.0", false},
+
+		// Caret on 0.x: same minor version
+		{"^0.2.3", "0.2.3", true},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.2.3", "1.0.0", false},
+		{"^0.0.3", "0.0.9", true},
+		{"^0.0.3", "0.1.0", false},
+		{"^0", "0.0.1", true},
+		{"^0", "0.1.0", false},
+
+		// Tilde: same minor version
+		{"~1.2.3", "1.2.3", true},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.2.2", false},
+		{"~1.2.3", "1.3.0", false},
+		{"~1.2", "1.2.0", true},
+		{"~0.1", "0.1.5", true},
+		{"~0.1", "0.2.0", false},
+
+		// Alternatives
+		{"^1.4 || ^2", "1.3.9", false},
+		{"^1.4 || ^2", "1.4.0", true},
+		{"^1.4 || ^2", "2.5.0", true},
+		{"^1.4 || ^2", "3.0.0", false},
+		{">=1.0.0 <2.0.0 || >=3.0.0", "2.5.0", false},
+		{">=1.0.0 <2.0.0 || >=3.0.0", "3.1.0", true},
+		{"1.0.0||2.0.0", "2.0.0", true},
+
+		// Comparators, separators and spelling
+		{">= 2.0, < 3.0", "2.5.0", true},
+		{">= 2.0, < 3.0", "3.0.0", false},
+		{">2.0.0", "2.0.0", false},
+		{"<=2.0.0", "2.0.0", true},
+		{"!=1.5.0", "1.5.0", false},
+		{"!=1.5.0", "1.5.1", true},
+		{"==1.2.3", "1.2.3", true},
+		{"=v1.2.3", "1.2.3", true},
+		{"1.2", "1.2.0", true},
+		{"1.2.3", "v1.2.3+build.5", true},
+
+		// Prereleases sort before their release
+		{">=1.0.0-rc.1", "1.0.0-rc.2", true},
+		{">=1.0.0-rc.1", "1.0.0-beta", false},
+		{">=1.0.0-rc.1", "1.0.0", true},
+		{"<1.0.0", "1.0.0-rc.1", false},
+		{">=1.0.0", "1.0.0-rc.1", false},
+		{">=1.2.0-beta", "1.2.0-rc.1", true},
+		{">=1.2.0-beta", "1.3.0-rc.1", false},
+		{">=2.0.0 <3.0.0", "3.0.0-rc.1", false},
+		{"^2", "3.0.0-rc.1", false},
+		{"^2.0.0-rc.1", "2.0.0-rc.2", true},
+		{"^2.0.0-rc.1", "2.1.0-rc.1", false},
+		{"<1.0.0 || >=1.0.0-rc.1", "1.0.0-rc.2", true},
+		{"=1.0.0-rc.1", "1.0.0-rc.1+build", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			got, err := Satisfies(tt.version, tt.constraint)
+			if err != nil {
+				t.Fatalf("Satisfies() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Satisfies(%q, %q) = %v, want %v", tt.version, tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		"||",
+		">=1.0 ||",
+		">=",
+		"^x",
+		"~1.2.3.4",
+		">>1.0",
+		"1.0.0-",
+	}
+
+	for _, constraint := range tests {
+		t.Run(constraint, func(t *testing.T) {
+			if _, err := ParseConstraint(constraint); !errors.Is(err, ErrInvalidConstraint) {
+				t.Errorf("ParseConstraint(%q) error = %v, want %v", constraint, err, ErrInvalidConstraint)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"v",
+		"1.2.x",
+		"1.2.3.4",
+		"1.0.0-",
+		"-1.0.0",
+		"1..0",
+	}
+
+	for _, version := range tests {
+		t.Run(version, func(t *testing.T) {
+			if _, err := Parse(version); !errors.Is(err, ErrInvalidVersion) {
+				t.Errorf("Parse(%q) error = %v, want %v", version, err, ErrInvalidVersion)
+			}
+		})
+	}
+}
+
+func TestComparePrereleaseOrdering(t *testing.T) {
+	// Ascending order from the semantic versioning specification
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1-0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+
+	versions := make([]Version, len(ordered))
+	for i, s := range ordered {
+		v, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", s, err)
+		}
+		versions[i] = v
+	}
+
+	for i := range versions {
+		for j := range versions {
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			if got := versions[i].Compare(versions[j]); got != want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
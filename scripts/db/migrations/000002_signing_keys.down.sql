@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_signing_keys_updated_at ON signing_keys;
+DROP TABLE IF EXISTS signing_keys;
@@ -0,0 +1,22 @@
+-- Signing keys table
+CREATE TABLE signing_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kid VARCHAR(64) NOT NULL UNIQUE,
+    algorithm VARCHAR(20) NOT NULL DEFAULT 'EdDSA',
+    public_key TEXT NOT NULL,
+    private_key TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    retired_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_signing_keys_status CHECK (status IN ('active', 'retiring', 'retired'))
+);
+
+-- Only one key may be active at a time
+CREATE UNIQUE INDEX idx_signing_keys_active ON signing_keys(status) WHERE status = 'active';
+CREATE INDEX idx_signing_keys_status ON signing_keys(status);
+
+CREATE TRIGGER update_signing_keys_updated_at
+    BEFORE UPDATE ON signing_keys
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS activations;
+
+ALTER TABLE licenses DROP COLUMN IF EXISTS max_activations;
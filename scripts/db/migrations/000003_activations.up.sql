@@ -0,0 +1,17 @@
+-- Per-license activation limit override
+ALTER TABLE licenses ADD COLUMN max_activations INTEGER;
+
+-- Machine activations table
+CREATE TABLE activations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    license_id UUID NOT NULL REFERENCES licenses(id) ON DELETE CASCADE,
+    machine_fingerprint VARCHAR(255) NOT NULL,
+    hostname VARCHAR(255),
+    platform VARCHAR(100),
+    ip_address VARCHAR(45),
+    first_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(license_id, machine_fingerprint)
+);
+
+CREATE INDEX idx_activations_license_id ON activations(license_id);
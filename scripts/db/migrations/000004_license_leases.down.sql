@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS license_leases;
+
+ALTER TABLE licenses DROP COLUMN IF EXISTS max_leases;
@@ -0,0 +1,17 @@
+-- Per-license concurrent lease limit override
+ALTER TABLE licenses ADD COLUMN max_leases INTEGER;
+
+-- Floating license leases table
+CREATE TABLE license_leases (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    license_id UUID NOT NULL REFERENCES licenses(id) ON DELETE CASCADE,
+    holder VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45),
+    ttl_seconds INTEGER NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_heartbeat_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_license_leases_license_id ON license_leases(license_id, expires_at);
+CREATE INDEX idx_license_leases_expires_at ON license_leases(expires_at);
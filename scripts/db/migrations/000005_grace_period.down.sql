@@ -0,0 +1,2 @@
+ALTER TABLE license_types DROP COLUMN IF EXISTS grace_period_days;
+ALTER TABLE applications DROP COLUMN IF EXISTS grace_period_days;
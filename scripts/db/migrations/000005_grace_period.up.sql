@@ -0,0 +1,3 @@
+-- Grace period after expiry, per application with per license type override
+ALTER TABLE applications ADD COLUMN grace_period_days INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE license_types ADD COLUMN grace_period_days INTEGER;
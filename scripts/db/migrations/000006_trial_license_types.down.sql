@@ -0,0 +1 @@
+ALTER TABLE license_types DROP COLUMN IF EXISTS is_trial;
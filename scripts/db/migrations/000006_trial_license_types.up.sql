@@ -0,0 +1,2 @@
+-- Trial license types
+ALTER TABLE license_types ADD COLUMN is_trial BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_licenses_reinstate_at;
+
+ALTER TABLE licenses DROP COLUMN IF EXISTS reinstate_at;
+ALTER TABLE licenses DROP COLUMN IF EXISTS suspended_at;
+ALTER TABLE licenses DROP COLUMN IF EXISTS suspension_reason;
+ALTER TABLE licenses DROP COLUMN IF EXISTS is_suspended;
@@ -0,0 +1,7 @@
+-- Reversible license suspension
+ALTER TABLE licenses ADD COLUMN is_suspended BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE licenses ADD COLUMN suspension_reason TEXT;
+ALTER TABLE licenses ADD COLUMN suspended_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE licenses ADD COLUMN reinstate_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_licenses_reinstate_at ON licenses(reinstate_at) WHERE is_suspended;
@@ -0,0 +1,5 @@
+ALTER TABLE applications DROP COLUMN IF EXISTS key_alphabet;
+ALTER TABLE applications DROP COLUMN IF EXISTS key_group_size;
+ALTER TABLE applications DROP COLUMN IF EXISTS key_groups;
+ALTER TABLE applications DROP COLUMN IF EXISTS key_prefix;
+ALTER TABLE applications DROP COLUMN IF EXISTS key_format;
@@ -0,0 +1,6 @@
+-- License key format, per application
+ALTER TABLE applications ADD COLUMN key_format VARCHAR(20) NOT NULL DEFAULT 'hex';
+ALTER TABLE applications ADD COLUMN key_prefix VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE applications ADD COLUMN key_groups INTEGER NOT NULL DEFAULT 4;
+ALTER TABLE applications ADD COLUMN key_group_size INTEGER NOT NULL DEFAULT 5;
+ALTER TABLE applications ADD COLUMN key_alphabet VARCHAR(36) NOT NULL DEFAULT '';
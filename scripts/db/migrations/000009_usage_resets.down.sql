@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS usage_history;
+
+ALTER TABLE licenses DROP COLUMN IF EXISTS usage_reset_periods;
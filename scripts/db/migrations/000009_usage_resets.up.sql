@@ -0,0 +1,17 @@
+-- Per-metric usage reset periods (daily, monthly, yearly)
+ALTER TABLE licenses ADD COLUMN usage_reset_periods JSONB NOT NULL DEFAULT '{}';
+
+-- Usage of finished reset periods
+CREATE TABLE usage_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    license_id UUID NOT NULL REFERENCES licenses(id) ON DELETE CASCADE,
+    metric VARCHAR(255) NOT NULL,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    used NUMERIC NOT NULL DEFAULT 0,
+    usage_limit NUMERIC,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (license_id, metric, period_start)
+);
+
+CREATE INDEX idx_usage_history_license_id ON usage_history(license_id, metric, period_start DESC);
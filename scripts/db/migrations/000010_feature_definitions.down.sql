@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_feature_definitions_updated_at ON feature_definitions;
+DROP TABLE IF EXISTS feature_definitions;
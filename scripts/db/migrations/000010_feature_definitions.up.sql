@@ -0,0 +1,18 @@
+-- Feature catalog, per application
+CREATE TABLE feature_definitions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    application_id UUID NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    key VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    description TEXT,
+    default_value JSONB,
+    enum_values JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (application_id, key)
+);
+
+CREATE TRIGGER update_feature_definitions_updated_at
+    BEFORE UPDATE ON feature_definitions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
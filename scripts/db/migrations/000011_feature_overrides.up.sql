@@ -0,0 +1,2 @@
+-- Per-license feature overrides; a null value removes the type's feature
+ALTER TABLE licenses ADD COLUMN feature_overrides JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+ALTER TABLE licenses DROP COLUMN IF EXISTS version_constraint;
+ALTER TABLE license_types DROP COLUMN IF EXISTS version_constraint;
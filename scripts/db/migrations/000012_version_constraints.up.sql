@@ -0,0 +1,3 @@
+-- Allowed application versions, per license type with per license override
+ALTER TABLE license_types ADD COLUMN version_constraint VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE licenses ADD COLUMN version_constraint VARCHAR(255) NOT NULL DEFAULT '';
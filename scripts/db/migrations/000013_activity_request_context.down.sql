@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_license_activities_ip_address;
+
+ALTER TABLE license_activities DROP COLUMN IF EXISTS principal;
+ALTER TABLE license_activities DROP COLUMN IF EXISTS request_id;
@@ -0,0 +1,5 @@
+-- Request context of license activities
+ALTER TABLE license_activities ADD COLUMN request_id VARCHAR(64);
+ALTER TABLE license_activities ADD COLUMN principal VARCHAR(255);
+
+CREATE INDEX idx_license_activities_ip_address ON license_activities(license_id, ip_address);
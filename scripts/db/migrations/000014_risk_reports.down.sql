@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_license_activities_created_at;
+
+DROP TABLE IF EXISTS license_risk_reports;
@@ -0,0 +1,15 @@
+-- Latest key sharing analysis per license
+CREATE TABLE license_risk_reports (
+    license_id UUID PRIMARY KEY REFERENCES licenses(id) ON DELETE CASCADE,
+    score INTEGER NOT NULL DEFAULT 0,
+    reasons JSONB NOT NULL DEFAULT '[]',
+    distinct_ips INTEGER NOT NULL DEFAULT 0,
+    distinct_fingerprints INTEGER NOT NULL DEFAULT 0,
+    window_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    window_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    suspended_at TIMESTAMP WITH TIME ZONE,
+    analyzed_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_license_risk_reports_score ON license_risk_reports(score DESC);
+CREATE INDEX idx_license_activities_created_at ON license_activities(created_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_licenses_expiry_date;
+
+DROP TABLE IF EXISTS expiry_notices;
@@ -0,0 +1,11 @@
+-- Expiry notices already sent, one per license, window and expiry date
+CREATE TABLE expiry_notices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    license_id UUID NOT NULL REFERENCES licenses(id) ON DELETE CASCADE,
+    window_days INTEGER NOT NULL,
+    expiry_date DATE NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (license_id, window_days, expiry_date)
+);
+
+CREATE INDEX idx_licenses_expiry_date ON licenses(expiry_date);
@@ -0,0 +1,10 @@
+UPDATE webhook_endpoints
+SET event_types = (
+    SELECT COALESCE(jsonb_agg(to_jsonb(
+        CASE WHEN t.value LIKE 'license.%' THEN substr(t.value, length('license.') + 1) ELSE t.value END
+    ) ORDER BY t.ordinality), '[]'::jsonb)
+    FROM jsonb_array_elements_text(event_types) WITH ORDINALITY AS t(value, ordinality)
+    WHERE t.value NOT LIKE 'client.%'
+);
+
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,27 @@
+-- Domain events written in the same transaction as the change they describe
+CREATE TABLE outbox (
+    id UUID PRIMARY KEY,
+    type VARCHAR(100) NOT NULL,
+    application_id UUID NOT NULL,
+    subject_id UUID NOT NULL,
+    data JSONB NOT NULL,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_error TEXT
+);
+
+CREATE INDEX idx_outbox_pending ON outbox(next_attempt_at) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_published_at ON outbox(published_at) WHERE published_at IS NOT NULL;
+
+-- Event types are now prefixed with their subject
+UPDATE webhook_endpoints
+SET event_types = (
+    SELECT COALESCE(jsonb_agg(
+        CASE WHEN t.value IN ('created', 'renewed', 'revoked', 'validated', 'expiring_soon')
+            THEN to_jsonb('license.' || t.value)
+            ELSE to_jsonb(t.value)
+        END ORDER BY t.ordinality), '[]'::jsonb)
+    FROM jsonb_array_elements_text(event_types) WITH ORDINALITY AS t(value, ordinality)
+);
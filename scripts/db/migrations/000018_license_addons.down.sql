@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS license_addons;
+ALTER TABLE license_types DROP COLUMN IF EXISTS is_addon;
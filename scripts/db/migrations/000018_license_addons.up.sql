@@ -0,0 +1,13 @@
+ALTER TABLE license_types ADD COLUMN is_addon BOOLEAN NOT NULL DEFAULT false;
+
+-- Add-on license types attached to a base license
+CREATE TABLE license_addons (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    license_id UUID NOT NULL REFERENCES licenses(id) ON DELETE CASCADE,
+    license_type_id UUID NOT NULL REFERENCES license_types(id),
+    start_date DATE NOT NULL,
+    expiry_date DATE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (license_id, license_type_id)
+);
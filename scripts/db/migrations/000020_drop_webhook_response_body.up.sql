@@ -0,0 +1,2 @@
+-- Response bodies from customer endpoints are no longer stored
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS response_body;
@@ -0,0 +1,17 @@
+ALTER TABLE webhook_deliveries ADD COLUMN redelivery_of UUID;
+
+-- Later copies of the same event count as redeliveries of the first one
+UPDATE webhook_deliveries d
+SET redelivery_of = first.id
+FROM (
+    SELECT DISTINCT ON (endpoint_id, event_id) id, endpoint_id, event_id
+    FROM webhook_deliveries
+    ORDER BY endpoint_id, event_id, created_at, id
+) first
+WHERE d.endpoint_id = first.endpoint_id
+  AND d.event_id = first.event_id
+  AND d.id <> first.id;
+
+-- An event is queued once per endpoint, redeliveries aside
+CREATE UNIQUE INDEX idx_webhook_deliveries_event ON webhook_deliveries(endpoint_id, event_id)
+    WHERE redelivery_of IS NULL;